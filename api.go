@@ -3,12 +3,18 @@ package grabexpress
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // CreateQuotes requests for delivery service quotes. When packages details aren't provided,
 // a single cheapest category package is assumed. Immediate dispatching is assumed.
 // An array of delivery services with their respective quote is returned.
 func (c *Client) CreateQuotes(ctx context.Context, req *CreateQuotesRequest) (*CreateQuotesResponse, error) {
+	if c.validateRequests {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	path := "/v1/deliveries/quotes"
 	resp := &CreateQuotesResponse{}
 	if err := c.post(ctx, path, req, resp); err != nil {
@@ -17,8 +23,18 @@ func (c *Client) CreateQuotes(ctx context.Context, req *CreateQuotesRequest) (*C
 	return resp, nil
 }
 
-// CreateDelivery ...
+// CreateDelivery books a delivery. When the client has an IdempotencyStore, a request whose idempotency key
+// was already used returns the existing delivery instead of booking a second one.
 func (c *Client) CreateDelivery(ctx context.Context, req *CreateDeliveryRequest) (*CreateDeliveryResponse, error) {
+	if c.validateRequests {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return c.createDeliveryOnce(ctx, req, time.Time{})
+}
+
+func (c *Client) createDelivery(ctx context.Context, req *CreateDeliveryRequest) (*CreateDeliveryResponse, error) {
 	path := "/v1/deliveries"
 	resp := &CreateDeliveryResponse{}
 	if err := c.post(ctx, path, req, resp); err != nil {
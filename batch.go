@@ -0,0 +1,121 @@
+package grabexpress
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchConcurrency is the number of concurrent API calls of a batch.
+const defaultBatchConcurrency = 8
+
+// BatchOptions configures BatchQuote.
+type BatchOptions struct {
+	// Concurrency is the number of concurrent API calls. Defaults to 8.
+	Concurrency int
+	// RatePerSecond limits how many API calls start per second. Zero means unlimited.
+	RatePerSecond float64
+	// Burst is the number of calls that may start at once within the rate limit. Defaults to 1.
+	Burst int
+}
+
+// BatchQuoteResult is the outcome of one request of a batch.
+type BatchQuoteResult struct {
+	// Index is the position of the request in the batch.
+	Index    int
+	Request  *CreateQuotesRequest
+	Response *CreateQuotesResponse
+	Err      error
+}
+
+// QuoteRequestsFromOrigin builds the requests quoting deliveries from origin to each destination.
+func QuoteRequestsFromOrigin(origin Waypoint, destinations []Waypoint, packages []Package, serviceType *ServiceType) []*CreateQuotesRequest {
+	reqs := make([]*CreateQuotesRequest, len(destinations))
+	for i, destination := range destinations {
+		reqs[i] = &CreateQuotesRequest{
+			ServiceType: serviceType,
+			Packages:    packages,
+			Origin:      origin,
+			Destination: destination,
+		}
+	}
+	return reqs
+}
+
+// BatchQuote requests quotes for every request, and returns the results in the order of reqs.
+// A failed request does not stop the batch: its error is reported in its result. Requests without
+// a result once ctx is done report ctx's error.
+func (c *Client) BatchQuote(ctx context.Context, reqs []*CreateQuotesRequest, opts BatchOptions) []BatchQuoteResult {
+	results := make([]BatchQuoteResult, len(reqs))
+	received := make([]bool, len(reqs))
+	for result := range c.BatchQuoteStream(ctx, reqs, opts) {
+		results[result.Index] = result
+		received[result.Index] = true
+	}
+	for i := range results {
+		if !received[i] {
+			results[i] = BatchQuoteResult{Index: i, Request: reqs[i], Err: ctx.Err()}
+		}
+	}
+	return results
+}
+
+// BatchQuoteStream requests quotes for every request, and emits the results as they complete.
+// The channel is closed once every request has a result, or once ctx is done: results not yet
+// received then are dropped, so a caller that stops reading must cancel ctx to release the batch.
+func (c *Client) BatchQuoteStream(ctx context.Context, reqs []*CreateQuotesRequest, opts BatchOptions) <-chan BatchQuoteResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	var limiter *tokenBucket
+	if opts.RatePerSecond > 0 {
+		limiter = newTokenBucket(opts.RatePerSecond, opts.Burst)
+	}
+
+	indexes := make(chan int)
+	results := make(chan BatchQuoteResult, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				select {
+				case results <- c.batchQuote(ctx, limiter, index, reqs[index]):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+	feed:
+		for index := range reqs {
+			select {
+			case indexes <- index:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(indexes)
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func (c *Client) batchQuote(ctx context.Context, limiter *tokenBucket, index int, req *CreateQuotesRequest) BatchQuoteResult {
+	result := BatchQuoteResult{Index: index, Request: req}
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+	if limiter != nil {
+		if err := limiter.wait(ctx); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	result.Response, result.Err = c.CreateQuotes(ctx, req)
+	return result
+}
@@ -0,0 +1,91 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+func newBatch(n int) []*grabexpress.CreateQuotesRequest {
+	reqs := make([]*grabexpress.CreateQuotesRequest, n)
+	for i := range reqs {
+		reqs[i] = newCreateQuotesRequest()
+		reqs[i].Destination.Coordinates.Latitude += float64(i) / 1000
+	}
+	return reqs
+}
+
+func TestBatchQuote(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := newBatch(5)
+	results := client.BatchQuote(context.Background(), reqs, grabexpress.BatchOptions{Concurrency: 2})
+	if len(results) != len(reqs) {
+		t.Fatalf("results = %d, want %d", len(results), len(reqs))
+	}
+	for i, result := range results {
+		if result.Index != i || result.Request != reqs[i] {
+			t.Errorf("result %d is for request %d", i, result.Index)
+		}
+		if result.Err != nil || result.Response == nil {
+			t.Errorf("result %d: response = %v, error = %v", i, result.Response, result.Err)
+		}
+	}
+}
+
+func TestBatchQuoteCanceled(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reqs := newBatch(5)
+	for i, result := range client.BatchQuote(ctx, reqs, grabexpress.BatchOptions{}) {
+		if result.Index != i || !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("result %d: index = %d, error = %v, want context.Canceled", i, result.Index, result.Err)
+		}
+	}
+}
+
+func TestBatchQuoteStreamStopsWhenAbandoned(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	results := client.BatchQuoteStream(ctx, newBatch(20), grabexpress.BatchOptions{Concurrency: 2})
+	<-results
+	// The caller stops reading, and cancels to release the batch: only the buffered results remain.
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	remaining := 0
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				if remaining > 2 {
+					t.Errorf("remaining results = %d, want at most 2", remaining)
+				}
+				return
+			}
+			remaining++
+		case <-timeout:
+			t.Fatal("stream not closed after ctx was canceled")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package grabexpress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrPriceDrift is matched by the error of QuoteAndBook when the booked price moved away from the quote.
+var ErrPriceDrift = errors.New("booked price drifted from the quote")
+
+// Order describes a delivery to quote and book with QuoteAndBook.
+type Order struct {
+	MerchantOrderID string
+	// IdempotencyKey identifies the booking client-side. Defaults to MerchantOrderID.
+	IdempotencyKey string
+	PaymentMethod  *PaymentMethod
+	Packages       []Package
+	CashOnDelivery *CashOnDelivery
+	Sender         Contact
+	Recipient      Contact
+	Origin         Waypoint
+	Destination    Waypoint
+	Schedule       *Schedule
+	// PriceTolerance is the fraction of the quoted amount by which the booked amount may differ,
+	// e.g. 0.05 for 5%. Zero requires the same amount.
+	PriceTolerance float64
+}
+
+// Booking is the outcome of QuoteAndBook.
+type Booking struct {
+	// Selection explains how the quote was selected.
+	Selection *Selection
+	// Quote is the selected quote.
+	Quote QuoteBase
+	// Delivery is the booked delivery.
+	Delivery *CreateDeliveryResponse
+}
+
+// PriceDriftError reports a booked amount differing from the quoted one by more than the tolerance.
+type PriceDriftError struct {
+	Quoted    Money
+	Booked    Money
+	Tolerance float64
+}
+
+// Error ...
+func (e *PriceDriftError) Error() string {
+	return fmt.Sprintf("%s: quoted %s, booked %s, tolerance %g%%", ErrPriceDrift, e.Quoted, e.Booked, e.Tolerance*100)
+}
+
+// Is makes errors.Is(err, ErrPriceDrift) hold.
+func (e *PriceDriftError) Is(target error) bool {
+	return target == ErrPriceDrift
+}
+
+// QuoteAndBook fetches quotes for order, selects one with policy, and books it.
+// When the booked amount drifted beyond order.PriceTolerance, the booking is returned along with
+// a *PriceDriftError; the delivery is not canceled, that decision is left to the caller.
+func (c *Client) QuoteAndBook(ctx context.Context, order *Order, policy SelectionPolicy) (*Booking, error) {
+	quotes, err := c.CreateQuotes(ctx, &CreateQuotesRequest{
+		ServiceType: policy.onlyServiceType(),
+		Packages:    order.Packages,
+		Origin:      order.Origin,
+		Destination: order.Destination,
+	})
+	if err != nil {
+		return nil, err
+	}
+	selection, err := quotes.SelectQuote(policy)
+	if err != nil {
+		return &Booking{Selection: selection}, err
+	}
+	booking := &Booking{Selection: selection, Quote: selection.Quote}
+	booking.Delivery, err = c.CreateDelivery(ctx, &CreateDeliveryRequest{
+		IdempotencyKey:  order.IdempotencyKey,
+		MerchantOrderID: order.MerchantOrderID,
+		ServiceType:     selection.Quote.Service.Type,
+		PaymentMethod:   order.PaymentMethod,
+		Packages:        order.Packages,
+		CashOnDelivery:  order.CashOnDelivery,
+		Sender:          order.Sender,
+		Recipient:       order.Recipient,
+		Origin:          order.Origin,
+		Destination:     order.Destination,
+		Schedule:        order.Schedule,
+	})
+	if err != nil {
+		return booking, err
+	}
+	return booking, checkPriceDrift(selection.Quote, booking.Delivery.Quote.QuoteBase, order.PriceTolerance)
+}
+
+// checkPriceDrift returns a *PriceDriftError when booked differs from quoted by more than tolerance.
+func checkPriceDrift(quoted, booked QuoteBase, tolerance float64) error {
+	q, b := quoted.AmountMoney(), booked.AmountMoney()
+	drift := &PriceDriftError{Quoted: q, Booked: b, Tolerance: tolerance}
+	diff, err := b.Sub(q)
+	if err != nil {
+		return drift
+	}
+	if math.Abs(float64(diff.Amount)) > math.Abs(float64(q.Amount))*tolerance {
+		return drift
+	}
+	return nil
+}
+
+// onlyServiceType returns the service type to request quotes for, when the policy accepts a single one.
+func (p SelectionPolicy) onlyServiceType() *ServiceType {
+	if len(p.ServiceTypes) != 1 {
+		return nil
+	}
+	t := p.ServiceTypes[0]
+	return &t
+}
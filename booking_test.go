@@ -0,0 +1,35 @@
+package grabexpress
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckPriceDrift(t *testing.T) {
+	sgd := Currency{Code: "SGD", Exponent: 2}
+	idr := Currency{Code: "IDR", Exponent: 0}
+	tests := []struct {
+		name      string
+		quoted    QuoteBase
+		booked    QuoteBase
+		tolerance float64
+		drifted   bool
+	}{
+		{"same amount", QuoteBase{Currency: sgd, Amount: 10}, QuoteBase{Currency: sgd, Amount: 10}, 0, false},
+		{"within tolerance", QuoteBase{Currency: sgd, Amount: 10}, QuoteBase{Currency: sgd, Amount: 10.5}, 0.05, false},
+		{"cheaper within tolerance", QuoteBase{Currency: sgd, Amount: 10}, QuoteBase{Currency: sgd, Amount: 9.5}, 0.05, false},
+		{"beyond tolerance", QuoteBase{Currency: sgd, Amount: 10}, QuoteBase{Currency: sgd, Amount: 10.51}, 0.05, true},
+		{"any change without tolerance", QuoteBase{Currency: sgd, Amount: 10}, QuoteBase{Currency: sgd, Amount: 10.01}, 0, true},
+		{"floating point amounts", QuoteBase{Currency: sgd, Amount: 0.1 + 0.2}, QuoteBase{Currency: sgd, Amount: 0.3}, 0, false},
+		{"other currency", QuoteBase{Currency: sgd, Amount: 10}, QuoteBase{Currency: idr, Amount: 10}, 0.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPriceDrift(tt.quoted, tt.booked, tt.tolerance)
+			var drift *PriceDriftError
+			if tt.drifted != errors.As(err, &drift) || tt.drifted != errors.Is(err, ErrPriceDrift) {
+				t.Errorf("checkPriceDrift() error = %v, want drift %t", err, tt.drifted)
+			}
+		})
+	}
+}
@@ -0,0 +1,558 @@
+package grabexpress
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ImportFormat ...
+type ImportFormat string
+
+// ImportFormat enum
+const (
+	// ImportFormatCSV - one delivery per row, with a header row naming the columns
+	ImportFormatCSV ImportFormat = "csv"
+	// ImportFormatJSONL - one CreateDeliveryRequest JSON object per line
+	ImportFormatJSONL ImportFormat = "jsonl"
+)
+
+// ImportStatus ...
+type ImportStatus string
+
+// ImportStatus enum
+const (
+	// ImportStatusBooked - the delivery was created
+	ImportStatusBooked ImportStatus = "BOOKED"
+	// ImportStatusFailed - the row is invalid, or the delivery could not be created
+	ImportStatusFailed ImportStatus = "FAILED"
+	// ImportStatusUnresolved - an earlier import left the creation in flight, and whether it was booked is unknown
+	ImportStatusUnresolved ImportStatus = "UNRESOLVED"
+)
+
+// defaultImportConcurrency is the number of deliveries an Importer books at once.
+const defaultImportConcurrency = 4
+
+// resultHeader is the header row of import result files.
+var resultHeader = []string{"row", "merchantOrderID", "status", "deliveryID", "trackingURL", "pickupPin", "error"}
+
+// ColumnMapping maps request field paths, e.g. "recipient.phone", to the CSV columns holding them.
+// Fields missing from the mapping are read from the column named after their path.
+type ColumnMapping map[string]string
+
+// ImportFields lists the request field paths an Importer reads from CSV rows. Each row describes a single package.
+var ImportFields = func() []string {
+	fields := make([]string, 0, len(importSetters))
+	for _, s := range importSetters {
+		fields = append(fields, s.field)
+	}
+	return fields
+}()
+
+// ImportRow is a delivery read from an import file.
+type ImportRow struct {
+	// Row is the position of the delivery in the file, starting at 1.
+	Row     int
+	Request *CreateDeliveryRequest
+	// Err reports why the row could not be parsed or is invalid.
+	Err error
+}
+
+// ImportResult is the outcome of booking an ImportRow.
+type ImportResult struct {
+	Row             int
+	MerchantOrderID string
+	Status          ImportStatus
+	DeliveryID      string
+	TrackingURL     string
+	PickupPin       string
+	Error           string
+}
+
+// ImportSummary counts the outcomes of an import.
+type ImportSummary struct {
+	Total     int
+	Skipped   int
+	Succeeded int
+	Failed    int
+	// Unresolved counts the rows whose delivery may or may not have been booked by an earlier import.
+	Unresolved int
+}
+
+// Importer books deliveries in bulk from CSV or JSONL files.
+// Configure the client with a FileIdempotencyStore so that an import resumed after a crash never books twice.
+// A delivery the crashed import left in flight is only created again once the client's delivery lookup,
+// see WithDeliveryLookup, confirms that it was not booked. Otherwise its row is reported as unresolved, to be
+// checked by hand, and the next import tries to resolve it again.
+type Importer struct {
+	client        *Client
+	mapping       ColumnMapping
+	concurrency   int
+	ratePerSecond float64
+	burst         int
+}
+
+// ImporterOption is the type of constructor options for NewImporter(...).
+type ImporterOption func(*Importer)
+
+// WithColumnMapping configures the CSV columns an Importer reads fields from
+func WithColumnMapping(mapping ColumnMapping) ImporterOption {
+	return func(im *Importer) {
+		im.mapping = mapping
+	}
+}
+
+// WithImportConcurrency configures how many deliveries an Importer books at once
+func WithImportConcurrency(n int) ImporterOption {
+	return func(im *Importer) {
+		if n > 0 {
+			im.concurrency = n
+		}
+	}
+}
+
+// WithImportRate configures how many deliveries an Importer books per second, with bursts of up to burst
+func WithImportRate(perSecond float64, burst int) ImporterOption {
+	return func(im *Importer) {
+		im.ratePerSecond = perSecond
+		im.burst = burst
+	}
+}
+
+// NewImporter constructs an Importer booking deliveries with client.
+func NewImporter(client *Client, options ...ImporterOption) *Importer {
+	im := &Importer{client: client, concurrency: defaultImportConcurrency}
+	for _, option := range options {
+		option(im)
+	}
+	return im
+}
+
+// Read parses and validates the deliveries of r.
+func (im *Importer) Read(r io.Reader, format ImportFormat) ([]ImportRow, error) {
+	var rows []ImportRow
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		rows, err = im.readCSV(r)
+	case ImportFormatJSONL:
+		rows, err = readJSONL(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if rows[i].Err == nil {
+			rows[i].Err = rows[i].Request.Validate()
+		}
+	}
+	return rows, nil
+}
+
+// Import books the deliveries of input, and appends the outcome of every row to the CSV file at resultPath.
+// Rows already booked according to an existing result file are skipped, so that an interrupted import can be
+// run again with the same arguments.
+func (im *Importer) Import(ctx context.Context, input io.Reader, format ImportFormat, resultPath string) (*ImportSummary, error) {
+	start := time.Now()
+	rows, err := im.Read(input, format)
+	if err != nil {
+		return nil, err
+	}
+	done, err := readImportResults(resultPath)
+	if err != nil {
+		return nil, err
+	}
+	out, err := openImportResults(resultPath)
+	if err != nil {
+		return nil, err
+	}
+	defer out.close()
+
+	summary := &ImportSummary{Total: len(rows)}
+	var pending []ImportRow
+	for _, row := range rows {
+		if row.Err == nil && done[row.Request.MerchantOrderID] {
+			summary.Skipped++
+			continue
+		}
+		pending = append(pending, row)
+	}
+
+	var limiter *tokenBucket
+	if im.ratePerSecond > 0 {
+		limiter = newTokenBucket(im.ratePerSecond, im.burst)
+	}
+	work := make(chan ImportRow)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var writeErr error
+	for i := 0; i < im.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range work {
+				result := im.book(ctx, limiter, row, start)
+				mu.Lock()
+				switch result.Status {
+				case ImportStatusBooked:
+					summary.Succeeded++
+				case ImportStatusUnresolved:
+					summary.Unresolved++
+				default:
+					summary.Failed++
+				}
+				if err := out.write(result); err != nil && writeErr == nil {
+					writeErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, row := range pending {
+		work <- row
+	}
+	close(work)
+	wg.Wait()
+	if writeErr != nil {
+		return summary, writeErr
+	}
+	return summary, ctx.Err()
+}
+
+// book creates the delivery of row. Creations left in flight before start were interrupted by an earlier import.
+func (im *Importer) book(ctx context.Context, limiter *tokenBucket, row ImportRow, start time.Time) ImportResult {
+	result := ImportResult{Row: row.Row}
+	if row.Request != nil {
+		result.MerchantOrderID = row.Request.MerchantOrderID
+	}
+	err := row.Err
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil && limiter != nil {
+		err = limiter.wait(ctx)
+	}
+	if err == nil {
+		var resp *CreateDeliveryResponse
+		if resp, err = im.client.createDeliveryOnce(ctx, row.Request, start); err == nil {
+			result.Status = ImportStatusBooked
+			result.DeliveryID = resp.DeliveryID
+			result.TrackingURL = resp.TrackingURL
+			result.PickupPin = resp.PickupPin
+			return result
+		}
+	}
+	result.Status = ImportStatusFailed
+	if errors.Is(err, ErrDeliveryInFlight) {
+		result.Status = ImportStatusUnresolved
+	}
+	result.Error = err.Error()
+	return result
+}
+
+func (im *Importer) readCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	// Rows with a wrong number of columns are reported individually.
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	var rows []ImportRow
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := ImportRow{Row: n}
+		if len(record) != len(header) {
+			row.Err = fmt.Errorf("row has %d columns, want %d", len(record), len(header))
+		} else {
+			row.Request = &CreateDeliveryRequest{}
+			row.Err = im.decodeRecord(record, columns, row.Request)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// decodeRecord sets the fields of req from the mapped columns of record.
+func (im *Importer) decodeRecord(record []string, columns map[string]int, req *CreateDeliveryRequest) error {
+	var pkg Package
+	hasPackage := false
+	var problems []string
+	for _, setter := range importSetters {
+		column := setter.field
+		if mapped, ok := im.mapping[setter.field]; ok {
+			column = mapped
+		}
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		if strings.HasPrefix(setter.field, "package.") {
+			hasPackage = true
+		}
+		if err := setter.set(req, &pkg, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", setter.field, err))
+		}
+	}
+	if hasPackage {
+		req.Packages = []Package{pkg}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func readJSONL(r io.Reader) ([]ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var rows []ImportRow
+	for n := 1; scanner.Scan(); {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		row := ImportRow{Row: n, Request: &CreateDeliveryRequest{}}
+		row.Err = json.Unmarshal(line, row.Request)
+		rows = append(rows, row)
+		n++
+	}
+	return rows, scanner.Err()
+}
+
+// readImportResults returns the merchant order IDs an existing result file reports as booked.
+func readImportResults(path string) (map[string]bool, error) {
+	done := map[string]bool{}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = len(resultHeader)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return done, nil
+		}
+		if err != nil {
+			// A crash may have truncated a line.
+			continue
+		}
+		if record[1] != "" && ImportStatus(record[2]) == ImportStatusBooked {
+			done[record[1]] = true
+		}
+	}
+}
+
+// importResults appends results to a CSV file, syncing every row so that they survive a crash.
+type importResults struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func openImportResults(path string) (*importResults, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	out := &importResults{f: f, w: csv.NewWriter(f)}
+	if err := out.start(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// start writes the header of a new file, or terminates the last line of an existing one,
+// which a crash may have truncated.
+func (out *importResults) start() error {
+	info, err := out.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return out.writeRecord(resultHeader)
+	}
+	last := make([]byte, 1)
+	if _, err := out.f.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+	if last[0] != '\n' {
+		if _, err := out.f.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (out *importResults) write(r ImportResult) error {
+	return out.writeRecord([]string{strconv.Itoa(r.Row), r.MerchantOrderID, string(r.Status), r.DeliveryID, r.TrackingURL, r.PickupPin, r.Error})
+}
+
+func (out *importResults) writeRecord(record []string) error {
+	if err := out.w.Write(record); err != nil {
+		return err
+	}
+	out.w.Flush()
+	if err := out.w.Error(); err != nil {
+		return err
+	}
+	return out.f.Sync()
+}
+
+func (out *importResults) close() error {
+	return out.f.Close()
+}
+
+// importSetter sets the request field at path field from a CSV value.
+type importSetter struct {
+	field string
+	set   func(req *CreateDeliveryRequest, pkg *Package, value string) error
+}
+
+var importSetters = buildImportSetters()
+
+func buildImportSetters() []importSetter {
+	setters := []importSetter{
+		{"merchantOrderID", func(r *CreateDeliveryRequest, _ *Package, v string) error { r.MerchantOrderID = v; return nil }},
+		{"idempotencyKey", func(r *CreateDeliveryRequest, _ *Package, v string) error { r.IdempotencyKey = v; return nil }},
+		{"serviceType", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			r.ServiceType = ServiceType(strings.ToUpper(v))
+			return nil
+		}},
+		{"paymentMethod", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			m := PaymentMethod(strings.ToUpper(v))
+			r.PaymentMethod = &m
+			return nil
+		}},
+	}
+	setters = append(setters, contactSetters("sender", func(r *CreateDeliveryRequest) *Contact { return &r.Sender })...)
+	setters = append(setters, contactSetters("recipient", func(r *CreateDeliveryRequest) *Contact { return &r.Recipient })...)
+	setters = append(setters, waypointSetters("origin", func(r *CreateDeliveryRequest) *Waypoint { return &r.Origin })...)
+	setters = append(setters, waypointSetters("destination", func(r *CreateDeliveryRequest) *Waypoint { return &r.Destination })...)
+	setters = append(setters,
+		importSetter{"package.name", func(_ *CreateDeliveryRequest, p *Package, v string) error { p.Name = v; return nil }},
+		importSetter{"package.description", func(_ *CreateDeliveryRequest, p *Package, v string) error { p.Description = v; return nil }},
+		importSetter{"package.quantity", intSetter(func(p *Package) *int64 { return &p.Quantity })},
+		importSetter{"package.price", func(_ *CreateDeliveryRequest, p *Package, v string) error {
+			return parseFloat(v, &p.Price)
+		}},
+		importSetter{"package.height", intSetter(func(p *Package) *int64 { return &p.Dimensions.Height })},
+		importSetter{"package.weight", intSetter(func(p *Package) *int64 { return &p.Dimensions.Weight })},
+		importSetter{"package.width", intSetter(func(p *Package) *int64 { return &p.Dimensions.Width })},
+		importSetter{"package.depth", intSetter(func(p *Package) *int64 { return &p.Dimensions.Depth })},
+		importSetter{"cashOnDelivery.amount", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			r.CashOnDelivery = &CashOnDelivery{}
+			return parseFloat(v, &r.CashOnDelivery.Amount)
+		}},
+		importSetter{"schedule.pickupTimeFrom", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			if r.Schedule == nil {
+				r.Schedule = &Schedule{}
+			}
+			return parseTime(v, &r.Schedule.PickupTimeFrom)
+		}},
+		importSetter{"schedule.pickupTimeTo", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			if r.Schedule == nil {
+				r.Schedule = &Schedule{}
+			}
+			return parseTime(v, &r.Schedule.PickupTimeTo)
+		}},
+	)
+	return setters
+}
+
+func contactSetters(prefix string, contact func(*CreateDeliveryRequest) *Contact) []importSetter {
+	str := func(field func(*Contact) *string) func(*CreateDeliveryRequest, *Package, string) error {
+		return func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			*field(contact(r)) = v
+			return nil
+		}
+	}
+	optional := func(field func(*Contact) **string) func(*CreateDeliveryRequest, *Package, string) error {
+		return func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			*field(contact(r)) = &v
+			return nil
+		}
+	}
+	return []importSetter{
+		{prefix + ".firstName", str(func(c *Contact) *string { return &c.FirstName })},
+		{prefix + ".lastName", optional(func(c *Contact) **string { return &c.LastName })},
+		{prefix + ".title", optional(func(c *Contact) **string { return &c.Title })},
+		{prefix + ".companyName", optional(func(c *Contact) **string { return &c.CompanyName })},
+		{prefix + ".email", str(func(c *Contact) *string { return &c.Email })},
+		{prefix + ".phone", str(func(c *Contact) *string { return &c.Phone })},
+		{prefix + ".smsEnabled", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			enabled, err := strconv.ParseBool(v)
+			contact(r).IsSmsEnabled = enabled
+			return err
+		}},
+		{prefix + ".instruction", optional(func(c *Contact) **string { return &c.Instruction })},
+	}
+}
+
+func waypointSetters(prefix string, waypoint func(*CreateDeliveryRequest) *Waypoint) []importSetter {
+	return []importSetter{
+		{prefix + ".address", func(r *CreateDeliveryRequest, _ *Package, v string) error { waypoint(r).Address = v; return nil }},
+		{prefix + ".keywords", func(r *CreateDeliveryRequest, _ *Package, v string) error { waypoint(r).Keywords = &v; return nil }},
+		{prefix + ".cityCode", func(r *CreateDeliveryRequest, _ *Package, v string) error { waypoint(r).CityCode = &v; return nil }},
+		{prefix + ".latitude", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			return parseFloat(v, &waypoint(r).Coordinates.Latitude)
+		}},
+		{prefix + ".longitude", func(r *CreateDeliveryRequest, _ *Package, v string) error {
+			return parseFloat(v, &waypoint(r).Coordinates.Longitude)
+		}},
+	}
+}
+
+func intSetter(field func(*Package) *int64) func(*CreateDeliveryRequest, *Package, string) error {
+	return func(_ *CreateDeliveryRequest, p *Package, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		*field(p) = n
+		return err
+	}
+}
+
+func parseFloat(v string, dst *float64) error {
+	f, err := strconv.ParseFloat(v, 64)
+	*dst = f
+	return err
+}
+
+func parseTime(v string, dst **time.Time) error {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return err
+	}
+	*dst = &t
+	return nil
+}
@@ -0,0 +1,156 @@
+package grabexpress_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+func TestImporterReadCSV(t *testing.T) {
+	input := "merchantOrderID,serviceType\nORDER-1,INSTANT\nORDER-2\nORDER-3,INSTANT,SAME_DAY\nORDER-4,SAME_DAY\n"
+	rows, err := grabexpress.NewImporter(nil).Read(strings.NewReader(input), grabexpress.ImportFormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("rows = %d, want 4", len(rows))
+	}
+	tests := []struct {
+		row             int
+		merchantOrderID string
+		columnErr       bool
+	}{
+		{row: 1, merchantOrderID: "ORDER-1"},
+		{row: 2, columnErr: true},
+		{row: 3, columnErr: true},
+		{row: 4, merchantOrderID: "ORDER-4"},
+	}
+	for i, tt := range tests {
+		row := rows[i]
+		if row.Row != tt.row {
+			t.Errorf("rows[%d].Row = %d, want %d", i, row.Row, tt.row)
+		}
+		if columnErr := row.Err != nil && strings.Contains(row.Err.Error(), "columns"); columnErr != tt.columnErr {
+			t.Errorf("row %d: error = %v, want column error %v", tt.row, row.Err, tt.columnErr)
+		}
+		if !tt.columnErr && row.Request.MerchantOrderID != tt.merchantOrderID {
+			t.Errorf("row %d: merchantOrderID = %q, want %q", tt.row, row.Request.MerchantOrderID, tt.merchantOrderID)
+		}
+	}
+}
+
+// importInput returns a JSONL import file booking the given merchant orders.
+func importInput(t *testing.T, merchantOrderIDs ...string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, id := range merchantOrderIDs {
+		if err := json.NewEncoder(&buf).Encode(newCreateDeliveryRequest(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &buf
+}
+
+// importStatuses returns the status of every merchant order written to the result file at path.
+func importStatuses(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]string{}
+	for _, record := range records[1:] {
+		statuses[record[1]] = record[2]
+	}
+	return statuses
+}
+
+func TestImporterResume(t *testing.T) {
+	tests := []struct {
+		name       string
+		lookup     grabexpress.DeliveryLookupFunc
+		status     grabexpress.ImportStatus
+		unresolved int
+		creations  int
+	}{
+		{
+			name:       "without lookup",
+			status:     grabexpress.ImportStatusUnresolved,
+			unresolved: 1,
+			creations:  1,
+		},
+		{
+			name: "lookup confirms absence",
+			lookup: func(ctx context.Context, merchantOrderID string) (*grabexpress.Delivery, error) {
+				return nil, nil
+			},
+			status:    grabexpress.ImportStatusBooked,
+			creations: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := grabexpresstest.NewServer()
+			defer srv.Close()
+			dir := t.TempDir()
+			store, err := grabexpress.NewFileIdempotencyStore(filepath.Join(dir, "idempotency.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A crashed import left ORDER-2 in flight.
+			if _, _, err := store.Reserve(context.Background(), "ORDER-2"); err != nil {
+				t.Fatal(err)
+			}
+			options := []grabexpress.ClientOption{grabexpress.WithIdempotencyStore(store)}
+			if tt.lookup != nil {
+				options = append(options, grabexpress.WithDeliveryLookup(tt.lookup))
+			}
+			client, err := srv.Client(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			importer := grabexpress.NewImporter(client)
+			resultPath := filepath.Join(dir, "results.csv")
+			summary, err := importer.Import(context.Background(), importInput(t, "ORDER-1", "ORDER-2"), grabexpress.ImportFormatJSONL, resultPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary.Unresolved != tt.unresolved {
+				t.Errorf("unresolved = %d, want %d", summary.Unresolved, tt.unresolved)
+			}
+			if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != tt.creations {
+				t.Errorf("creations = %d, want %d", n, tt.creations)
+			}
+			statuses := importStatuses(t, resultPath)
+			if statuses["ORDER-1"] != string(grabexpress.ImportStatusBooked) || statuses["ORDER-2"] != string(tt.status) {
+				t.Errorf("statuses = %v, want ORDER-1 booked and ORDER-2 %s", statuses, tt.status)
+			}
+
+			// Running the import again skips the booked rows only.
+			summary, err = importer.Import(context.Background(), importInput(t, "ORDER-1", "ORDER-2"), grabexpress.ImportFormatJSONL, resultPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary.Skipped != 2-tt.unresolved || summary.Unresolved != tt.unresolved {
+				t.Errorf("summary = %+v, want %d skipped and %d unresolved", summary, 2-tt.unresolved, tt.unresolved)
+			}
+			if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != tt.creations {
+				t.Errorf("creations after rerun = %d, want %d", n, tt.creations)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
@@ -21,9 +22,33 @@ type Client struct {
 	secret     string
 	baseURL    string
 	tokenURL   string
+	scopes     []string
 	oauth      clientcredentials.Config
+	timeout    time.Duration
+
+	tokenStore       TokenStore
+	tokenExpiryDelta time.Duration
+	tokenMiddlewares []TokenMiddleware
+	tokens           *tokenSource
+
+	retryPolicy RetryPolicy
+	rateLimiter *rateLimiter
+	middlewares []Middleware
+	roundTrip   RoundTripFunc
+
+	metrics *Metrics
+	logger  *callLogger
+
+	idempotencyStore IdempotencyStore
+	inFlightTimeout  time.Duration
+	deliveryLookup   DeliveryLookupFunc
+
+	validateRequests bool
 }
 
+// requestIDHeader carries the ID the API assigned to a request.
+const requestIDHeader = "X-Grabkit-Grab-Requestid"
+
 // DTO ...
 type DTO interface {
 	SetRequestID(id string)
@@ -33,28 +58,38 @@ type DTO interface {
 type ClientOption func(*Client) error
 
 // NewClient constructs a new Client which can make requests to the GrabExpress APIs.
+// All configuration problems are reported together as a *ConfigError.
 func NewClient(options ...ClientOption) (*Client, error) {
 	c := &Client{}
+	var problems []error
 	for _, option := range options {
-		err := option(c)
-		if err != nil {
-			return nil, err
+		if err := option(c); err != nil {
+			problems = append(problems, err)
 		}
 	}
-	if strings.TrimSpace(c.apiKey) == "" || strings.TrimSpace(c.secret) == "" {
-		return nil, ErrCredentialsMissing
+	problems = append(problems, c.validate()...)
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
 	}
-	if strings.TrimSpace(c.baseURL) == "" {
-		return nil, ErrBaseURLMissing
+	if c.timeout > 0 {
+		c.httpClient = withTimeout(c.httpClient, c.timeout)
 	}
-	if strings.TrimSpace(c.baseURL) == "" {
-		return nil, ErrTokenURLMissing
+	if len(c.scopes) == 0 {
+		c.scopes = defaultScopes
 	}
 	c.oauth = clientcredentials.Config{
 		ClientID:     c.apiKey,
 		ClientSecret: c.secret,
 		TokenURL:     c.tokenURL,
-		Scopes:       []string{"grab_express.partner_deliveries"},
+		Scopes:       c.scopes,
+	}
+	c.tokens = newTokenSource(c)
+	c.roundTrip = c.chain(c.exchange)
+	if c.logger != nil {
+		c.roundTrip = c.logger.middleware(c.roundTrip)
+	}
+	if c.metrics != nil {
+		c.roundTrip = c.metrics.middleware(c.roundTrip)
 	}
 	return c, nil
 }
@@ -103,45 +138,112 @@ func WithTokenURL(tokenURL string) ClientOption {
 }
 
 func (c *Client) get(ctx context.Context, path string, apiReq interface{}, apiResp DTO) error {
-	req, err := c.createRequest(ctx, http.MethodGet, path, apiReq)
-	if err != nil {
-		return wrapError(err)
-	}
-	return c.do(ctx, req, apiResp)
+	return c.send(ctx, http.MethodGet, path, apiReq, apiResp)
 }
 
 func (c *Client) post(ctx context.Context, path string, apiReq interface{}, apiResp DTO) error {
-	req, err := c.createRequest(ctx, http.MethodPost, path, apiReq)
-	if err != nil {
-		return wrapError(err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	return c.do(ctx, req, apiResp)
+	return c.send(ctx, http.MethodPost, path, apiReq, apiResp)
 }
 
 func (c *Client) put(ctx context.Context, path string, apiReq interface{}, apiResp DTO) error {
-	req, err := c.createRequest(ctx, http.MethodPut, path, apiReq)
-	if err != nil {
-		return wrapError(err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	return c.do(ctx, req, apiResp)
+	return c.send(ctx, http.MethodPut, path, apiReq, apiResp)
 }
 
 func (c *Client) delete(ctx context.Context, path string, apiReq interface{}, apiResp DTO) error {
-	req, err := c.createRequest(ctx, http.MethodDelete, path, apiReq)
+	return c.send(ctx, http.MethodDelete, path, apiReq, apiResp)
+}
+
+// send performs an API round-trip through the client middlewares.
+func (c *Client) send(ctx context.Context, method, path string, apiReq interface{}, apiResp DTO) error {
+	endpoint := endpointOf(path)
+	call := &Call{
+		Operation: operationOf(method, endpoint),
+		Endpoint:  endpoint,
+		Method:    method,
+		Path:      path,
+		Request:   apiReq,
+		Response:  apiResp,
+		Header:    http.Header{},
+		Start:     time.Now(),
+	}
+	return c.roundTrip(ctx, call)
+}
+
+// exchange performs an API round-trip, retrying transient failures according to the client's retry policy.
+func (c *Client) exchange(ctx context.Context, call *Call) error {
+	body, err := marshalRequest(call.Request)
 	if err != nil {
 		return wrapError(err)
 	}
-	return c.do(ctx, req, apiResp)
+	policy := c.retryPolicy
+	idempotent := isRetryableRequest(call.Method, call.Request)
+	for attempt := 1; ; attempt++ {
+		waited, apiErr := c.rateLimiter.wait(ctx, call.Endpoint)
+		c.metrics.observeRateLimitWait(call.Endpoint, call.Operation, waited)
+		if apiErr != nil {
+			return apiErr
+		}
+		call.Attempts = attempt
+		resp, apiErr := c.attempt(ctx, call, body)
+		c.rateLimiter.observe(call.Endpoint, resp)
+		if resp != nil {
+			call.Status = resp.StatusCode
+			call.ResponseHeader = resp.Header
+		}
+
+		var delay time.Duration
+		retry := attempt < policy.MaxAttempts && (idempotent || isUnprocessed(resp, apiErr))
+		if apiErr != nil {
+			retry = retry && apiErr.Retryable()
+		} else {
+			retry = retry && isRetryableStatus(resp.StatusCode)
+			delay = retryAfter(resp.Header, time.Now())
+		}
+		if retry {
+			if backoff := policy.backoff(attempt); backoff > delay {
+				delay = backoff
+			}
+			retry = policy.allows(ctx, call.Start, delay)
+		}
+		if !retry {
+			if apiErr != nil {
+				return apiErr
+			}
+			defer resp.Body.Close()
+			return decodeResponse(resp, call.Response)
+		}
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return wrapError(err)
+		}
+	}
 }
 
-func (c *Client) createRequest(ctx context.Context, method, path string, apiReq interface{}) (*http.Request, error) {
-	body, err := marshalRequest(apiReq)
+// attempt sends the request once. Unsuccessful responses are returned as is, without an error.
+func (c *Client) attempt(ctx context.Context, call *Call, body []byte) (*http.Response, *Error) {
+	req, err := c.createRequest(ctx, call.Method, call.Path, body)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
+	}
+	for name, values := range call.Header {
+		req.Header[name] = values
+	}
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, newTransportError(ctx, err)
+	}
+	return resp, nil
+}
+
+func (c *Client) createRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
 	}
-	req, err := http.NewRequest(method, c.baseURL+path, body)
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
 	if err != nil {
 		return nil, err
 	}
@@ -150,66 +252,58 @@ func (c *Client) createRequest(ctx context.Context, method, path string, apiReq
 		return nil, err
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+	if method == http.MethodPost || method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	return req, nil
 }
 
-func (c *Client) do(ctx context.Context, req *http.Request, apiResp DTO) error {
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
 	client := c.httpClient
 	if client == nil {
 		client = http.DefaultClient
 	}
 	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
-		return wrapError(err)
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.tokens.invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
 	}
-	defer resp.Body.Close()
-	return decodeResponse(resp, apiResp)
+	return resp, nil
 }
 
 func (c *Client) generateAuth(ctx context.Context) (*oauth2.Token, error) {
-	token, err := c.oauth.Token(ctx)
+	token, err := c.tokens.Token(ctx)
 	if err != nil {
-		return nil, ErrAuthenticationError
+		return nil, newAuthError(ctx, err)
 	}
 	return token, nil
 }
 
-func marshalRequest(apiReq interface{}) (io.Reader, error) {
+func marshalRequest(apiReq interface{}) ([]byte, error) {
 	if apiReq == nil {
 		return nil, nil
 	}
-	body, err := json.Marshal(apiReq)
-	if err != nil {
-		return nil, err
-	}
-	return bytes.NewBuffer(body), nil
+	return json.Marshal(apiReq)
 }
 
 func decodeResponse(resp *http.Response, apiResp DTO) error {
-	requestID := resp.Header.Get("X-Grabkit-Grab-Requestid")
+	requestID := resp.Header.Get(requestIDHeader)
 	apiResp.SetRequestID(requestID)
 
 	switch resp.StatusCode {
 	case http.StatusOK:
 		if err := json.NewDecoder(resp.Body).Decode(apiResp); err != nil {
-			return wrapError(err)
+			apiErr := wrapError(err)
+			apiErr.Status = resp.StatusCode
+			apiErr.RequestID = requestID
+			return apiErr
 		}
 		return nil
 	case http.StatusNoContent:
 		return nil
 	default:
-		var msg string
-		if resp.ContentLength != 0 {
-			bb, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				return wrapError(err)
-			}
-			msg = string(bb)
-		}
-		return &Error{
-			Status:    resp.StatusCode,
-			Message:   msg,
-			RequestID: requestID,
-		}
+		return newResponseError(resp, requestID)
 	}
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables selecting the profile. The profile settings themselves may be overridden
+// by the GRABEXPRESS_* variables read by grabexpress.Config.ApplyEnv.
+const (
+	envProfile = "GRABEXPRESS_PROFILE"
+	envConfig  = "GRABEXPRESS_CONFIG"
+)
+
+// configFile is the profile file, by default ~/.config/grabexpress/config.yaml:
+//
+//	profiles:
+//	  staging:
+//	    apiKey: ...
+//	    secret: ...
+//	    environment: staging
+type configFile struct {
+	Profiles map[string]grabexpress.Config `yaml:"profiles"`
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv(envConfig); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "grabexpress", "config.yaml")
+}
+
+// loadProfile reads the named profile from the file at path, then applies the environment variables.
+// A missing file is not an error, as everything may come from the environment.
+func loadProfile(path, name string) (grabexpress.Config, error) {
+	var p grabexpress.Config
+	if name == "" {
+		name = os.Getenv(envProfile)
+	}
+	if path != "" {
+		bb, err := ioutil.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			if name != "" {
+				return p, fmt.Errorf("profile %q: config file %s not found", name, path)
+			}
+		case err != nil:
+			return p, err
+		default:
+			var cfg configFile
+			if err := yaml.Unmarshal(bb, &cfg); err != nil {
+				return p, fmt.Errorf("config file %s: %v", path, err)
+			}
+			if name == "" {
+				name = "default"
+			}
+			found, ok := cfg.Profiles[name]
+			if !ok && name != "default" {
+				return p, fmt.Errorf("profile %q not found in %s", name, path)
+			}
+			p = found
+		}
+	}
+	return p, p.ApplyEnv()
+}
@@ -0,0 +1,393 @@
+// Command grabexpress is a command-line client for the GrabExpress APIs.
+//
+// Usage:
+//
+//	grabexpress <command> [flags] [arguments]
+//
+// Commands:
+//
+//	quote   -f request.json    request delivery quotes
+//	create  -f request.json    book a delivery
+//	get     <deliveryID>       show a delivery
+//	cancel  <deliveryID>       cancel a delivery
+//	track   <deliveryID>...    follow deliveries until they end
+//	token                      print an access token
+//
+// Credentials and endpoints are read from a profile of the config file, then from the
+// GRABEXPRESS_* environment variables, e.g. GRABEXPRESS_API_KEY, GRABEXPRESS_SECRET and
+// GRABEXPRESS_ENVIRONMENT (staging or production). See grabexpress.Config for the full list.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+const usage = `Usage: grabexpress <command> [flags] [arguments]
+
+Commands:
+  quote   -f request.json    request delivery quotes
+  create  -f request.json    book a delivery
+  get     <deliveryID>       show a delivery
+  cancel  <deliveryID>       cancel a delivery
+  track   <deliveryID>...    follow deliveries until they end
+  token                      print an access token
+
+Run 'grabexpress <command> -h' for the flags of a command.
+`
+
+// errUsage reports a command line that could not be understood.
+var errUsage = errors.New("invalid usage")
+
+// command is the parsed command line of a subcommand.
+type command struct {
+	flags    *flag.FlagSet
+	output   string
+	profile  string
+	config   string
+	dryRun   bool
+	file     string
+	interval time.Duration
+	stdout   io.Writer
+	stdin    io.Reader
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout)
+	cancel()
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, "grabexpress:", err)
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "grabexpress:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+	name, args := args[0], args[1:]
+	cmd := &command{flags: flag.NewFlagSet(name, flag.ContinueOnError), stdin: stdin, stdout: stdout}
+	cmd.flags.StringVar(&cmd.output, "o", outputTable, "output mode: table, json or yaml")
+	cmd.flags.StringVar(&cmd.profile, "profile", "", "config file profile, defaults to $"+envProfile+" or \"default\"")
+	cmd.flags.StringVar(&cmd.config, "config", defaultConfigPath(), "config file path")
+
+	var handler func(context.Context, *command) error
+	switch name {
+	case "quote":
+		cmd.flags.StringVar(&cmd.file, "f", "-", "JSON CreateQuotesRequest file, - for stdin")
+		cmd.flags.BoolVar(&cmd.dryRun, "dry-run", false, "print the request instead of sending it")
+		handler = runQuote
+	case "create":
+		cmd.flags.StringVar(&cmd.file, "f", "-", "JSON CreateDeliveryRequest file, - for stdin")
+		cmd.flags.BoolVar(&cmd.dryRun, "dry-run", false, "print the request instead of sending it")
+		handler = runCreate
+	case "get":
+		cmd.flags.BoolVar(&cmd.dryRun, "dry-run", false, "print the request instead of sending it")
+		handler = runGet
+	case "cancel":
+		cmd.flags.BoolVar(&cmd.dryRun, "dry-run", false, "print the request instead of sending it")
+		handler = runCancel
+	case "track":
+		cmd.flags.DurationVar(&cmd.interval, "interval", 5*time.Second, "polling interval")
+		handler = runTrack
+	case "token":
+		handler = runToken
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown command %q, see 'grabexpress help'", errUsage, name)
+	}
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	switch cmd.output {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("%w: unknown output mode %q", errUsage, cmd.output)
+	}
+	return handler(ctx, cmd)
+}
+
+// parse parses the flags, which may be interleaved with the positional arguments.
+func (cmd *command) parse(args []string) error {
+	var positional []string
+	for {
+		if err := cmd.flags.Parse(args); err != nil {
+			return err
+		}
+		args = cmd.flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return cmd.flags.Parse(append([]string{"--"}, positional...))
+}
+
+// client constructs the API client from the profile and environment. Failed requests are retried
+// unless retry is false, e.g. for bookings that a retry could duplicate.
+func (cmd *command) client(retry bool) (*grabexpress.Client, error) {
+	cfg, err := loadProfile(cmd.config, cmd.profile)
+	if err != nil {
+		return nil, err
+	}
+	if !retry {
+		cfg.RetryAttempts = 0
+	} else if cfg.RetryAttempts == 0 {
+		cfg.RetryAttempts = grabexpress.DefaultRetryPolicy.MaxAttempts
+	}
+	return grabexpress.NewClientFromConfig(cfg)
+}
+
+// printDryRun prints the request instead of sending it.
+func (cmd *command) printDryRun(method, path string, body interface{}) error {
+	cfg, err := loadProfile(cmd.config, cmd.profile)
+	if err != nil {
+		return err
+	}
+	env, err := cfg.Endpoints()
+	if err != nil {
+		return err
+	}
+	return renderDryRun(cmd.stdout, cmd.output, dryRun{Method: method, URL: strings.TrimRight(env.BaseURL, "/") + path, Body: body})
+}
+
+// readRequest decodes the JSON request of the -f flag into v.
+func (cmd *command) readRequest(v interface{}) error {
+	var r io.Reader = cmd.stdin
+	if cmd.file != "-" {
+		f, err := os.Open(cmd.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bb, v)
+}
+
+// deliveryIDs returns the positional arguments, requiring at least one, or exactly one when single.
+func (cmd *command) deliveryIDs(single bool) ([]string, error) {
+	ids := cmd.flags.Args()
+	if len(ids) == 0 || (single && len(ids) > 1) {
+		return nil, fmt.Errorf("%w: %s expects a delivery ID", errUsage, cmd.flags.Name())
+	}
+	return ids, nil
+}
+
+func runQuote(ctx context.Context, cmd *command) error {
+	req := &grabexpress.CreateQuotesRequest{}
+	if err := cmd.readRequest(req); err != nil {
+		return err
+	}
+	if cmd.dryRun {
+		return cmd.printDryRun("POST", "/v1/deliveries/quotes", req)
+	}
+	client, err := cmd.client(true)
+	if err != nil {
+		return err
+	}
+	resp, err := client.CreateQuotes(ctx, req)
+	if err != nil {
+		return err
+	}
+	return render(cmd.stdout, cmd.output, resp)
+}
+
+func runCreate(ctx context.Context, cmd *command) error {
+	req := &grabexpress.CreateDeliveryRequest{}
+	if err := cmd.readRequest(req); err != nil {
+		return err
+	}
+	if cmd.dryRun {
+		return cmd.printDryRun("POST", "/v1/deliveries", req)
+	}
+	client, err := cmd.client(false)
+	if err != nil {
+		return err
+	}
+	resp, err := client.CreateDelivery(ctx, req)
+	if err != nil {
+		return err
+	}
+	return render(cmd.stdout, cmd.output, &resp.Delivery)
+}
+
+func runGet(ctx context.Context, cmd *command) error {
+	ids, err := cmd.deliveryIDs(true)
+	if err != nil {
+		return err
+	}
+	if cmd.dryRun {
+		return cmd.printDryRun("GET", "/v1/deliveries/"+ids[0], nil)
+	}
+	client, err := cmd.client(true)
+	if err != nil {
+		return err
+	}
+	resp, err := client.GetDelivery(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+	return render(cmd.stdout, cmd.output, &resp.Delivery)
+}
+
+func runCancel(ctx context.Context, cmd *command) error {
+	ids, err := cmd.deliveryIDs(true)
+	if err != nil {
+		return err
+	}
+	if cmd.dryRun {
+		return cmd.printDryRun("DELETE", "/v1/deliveries/"+ids[0], nil)
+	}
+	client, err := cmd.client(true)
+	if err != nil {
+		return err
+	}
+	resp, err := client.CancelDelivery(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+	return render(cmd.stdout, cmd.output, resp)
+}
+
+func runToken(ctx context.Context, cmd *command) error {
+	client, err := cmd.client(true)
+	if err != nil {
+		return err
+	}
+	token, err := client.Token(ctx)
+	if err != nil {
+		return err
+	}
+	return render(cmd.stdout, cmd.output, token)
+}
+
+// activeStatuses are the statuses a tracked delivery is polled in, every --interval.
+var activeStatuses = []grabexpress.OrderStatus{
+	grabexpress.OrderStatusQueueing,
+	grabexpress.OrderStatusAllocating,
+	grabexpress.OrderStatusPickingUp,
+	grabexpress.OrderStatusInDelivery,
+	grabexpress.OrderStatusInReturn,
+}
+
+// runTrack follows deliveries until they all reach a terminal status. In table mode the table is redrawn
+// on every change; other modes print each changed delivery.
+func runTrack(ctx context.Context, cmd *command) error {
+	ids, err := cmd.deliveryIDs(false)
+	if err != nil {
+		return err
+	}
+	client, err := cmd.client(true)
+	if err != nil {
+		return err
+	}
+	options := []grabexpress.TrackerOption{grabexpress.WithDefaultPollInterval(cmd.interval)}
+	for _, status := range activeStatuses {
+		options = append(options, grabexpress.WithPollInterval(status, cmd.interval))
+	}
+	tracker := grabexpress.NewTracker(client, options...)
+	if err := tracker.Watch(ids...); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- tracker.Run(ctx) }()
+
+	latest := map[string]*grabexpress.Delivery{}
+	redraw := isTerminal(cmd.stdout)
+	for event := range tracker.Events() {
+		if event.Type == grabexpress.TrackerEventError {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", event.DeliveryID, event.Err)
+			continue
+		}
+		if latest[event.DeliveryID] == event.Delivery {
+			// A single poll may emit several events.
+			continue
+		}
+		latest[event.DeliveryID] = event.Delivery
+		if err := cmd.renderTracking(latest, event.Delivery, redraw); err != nil {
+			return err
+		}
+		if allTerminal(latest, ids) {
+			cancel()
+		}
+	}
+	if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+func (cmd *command) renderTracking(latest map[string]*grabexpress.Delivery, changed *grabexpress.Delivery, redraw bool) error {
+	if cmd.output != outputTable {
+		return render(cmd.stdout, cmd.output, changed)
+	}
+	deliveries := make([]grabexpress.Delivery, 0, len(latest))
+	for _, d := range latest {
+		deliveries = append(deliveries, *d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].DeliveryID < deliveries[j].DeliveryID })
+	if redraw {
+		fmt.Fprint(cmd.stdout, "\033[H\033[2J")
+	}
+	return render(cmd.stdout, cmd.output, deliveries)
+}
+
+func allTerminal(latest map[string]*grabexpress.Delivery, ids []string) bool {
+	for _, id := range ids {
+		d, ok := latest[id]
+		if !ok || !d.Status.IsTerminal() {
+			return false
+		}
+	}
+	return true
+}
+
+// isTerminal reports whether w is an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
+)
+
+// Output modes.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// render writes v to w in the given output mode.
+func render(w io.Writer, mode string, v interface{}) error {
+	switch mode {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		// Going through JSON keeps the API field names.
+		bb, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(bb, &generic); err != nil {
+			return err
+		}
+		return yaml.NewEncoder(w).Encode(generic)
+	case outputTable:
+		return renderTable(w, v)
+	}
+	return fmt.Errorf("unknown output mode %q", mode)
+}
+
+func renderTable(w io.Writer, v interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	switch v := v.(type) {
+	case *grabexpress.CreateQuotesResponse:
+		fmt.Fprintln(tw, "SERVICE\tTYPE\tAMOUNT\tDISTANCE\tPICKUP\tDROPOFF")
+		for _, q := range v.Quotes {
+			var pickup, dropoff *time.Time
+			if q.EstimatedTimeline != nil {
+				pickup, dropoff = q.EstimatedTimeline.Pickup, q.EstimatedTimeline.DropOff
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f km\t%s\t%s\n", q.Service.Name, q.Service.Type, q.AmountMoney(),
+				float64(q.Distance)/1000, formatTime(pickup), formatTime(dropoff))
+		}
+	case *grabexpress.Delivery:
+		renderDelivery(tw, v)
+	case *grabexpress.CancelDeliveryResponse:
+		fmt.Fprintf(tw, "CANCELED\tyes\nREQUEST ID\t%s\n", v.RequestID)
+	case *oauth2.Token:
+		fmt.Fprintf(tw, "ACCESS TOKEN\t%s\nTYPE\t%s\nEXPIRY\t%s\n", v.AccessToken, v.TokenType, v.Expiry.Format(time.RFC3339))
+	case []grabexpress.Delivery:
+		fmt.Fprintln(tw, "DELIVERY\tSTATUS\tPROGRESS\tCOURIER\tPOSITION\tUPDATED")
+		for _, d := range v {
+			renderTrackingRow(tw, &d)
+		}
+	default:
+		return fmt.Errorf("no table output for %T", v)
+	}
+	return tw.Flush()
+}
+
+func renderDelivery(w io.Writer, d *grabexpress.Delivery) {
+	rows := [][2]string{
+		{"DELIVERY ID", d.DeliveryID},
+		{"MERCHANT ORDER ID", d.MerchantOrderID},
+		{"STATUS", string(d.Status)},
+		{"SERVICE", fmt.Sprintf("%s (%s)", d.Quote.Service.Name, d.Quote.Service.Type)},
+		{"AMOUNT", d.Quote.AmountMoney().String()},
+		{"PAYMENT METHOD", string(d.PaymentMethod)},
+		{"TRACKING URL", d.TrackingURL},
+		{"PICKUP PIN", d.PickupPin},
+		{"INVOICE NUMBER", d.InvoiceNumber},
+		{"ORIGIN", d.Quote.Origin.Address},
+		{"DESTINATION", d.Quote.Destination.Address},
+	}
+	if d.Courier != nil {
+		rows = append(rows, [2]string{"COURIER", fmt.Sprintf("%s, %s (%s)", d.Courier.Name, d.Courier.Vehicle.LicensePlate, d.Courier.Phone)})
+	}
+	if d.AdvanceInfo != nil && d.AdvanceInfo.FailedReason != "" {
+		rows = append(rows, [2]string{"FAILED REASON", d.AdvanceInfo.FailedReason})
+	}
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\n", row[0], row[1])
+	}
+}
+
+func renderTrackingRow(w io.Writer, d *grabexpress.Delivery) {
+	courier, position := "-", "-"
+	if c := d.Courier; c != nil {
+		courier = c.Name
+		position = fmt.Sprintf("%.5f,%.5f", c.Coordinates.Latitude, c.Coordinates.Longitude)
+	}
+	progress := d.Progress()
+	fmt.Fprintf(w, "%s\t%s\t%d%%\t%s\t%s\t%s\n", d.DeliveryID, d.Status, progress.Percent(), courier, position,
+		formatTime(progress.UpdatedAt))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// dryRun describes a request that would have been sent.
+type dryRun struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+func renderDryRun(w io.Writer, mode string, d dryRun) error {
+	if mode != outputTable {
+		return render(w, mode, d)
+	}
+	fmt.Fprintf(w, "%s %s\n", d.Method, d.URL)
+	if d.Body == nil {
+		return nil
+	}
+	bb, err := json.MarshalIndent(d.Body, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, strings.TrimSpace(string(bb)))
+	return err
+}
@@ -0,0 +1,269 @@
+package grabexpress
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidURL     = errors.New("invalid URL")
+	ErrInvalidTimeout = errors.New("invalid timeout")
+)
+
+// ConfigError lists every problem found in a client configuration.
+// errors.Is matches any of them, e.g. errors.Is(err, grabexpress.ErrTokenURLMissing).
+type ConfigError struct {
+	Problems []error
+}
+
+// Error returns error message.
+func (e *ConfigError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		msgs[i] = problem.Error()
+	}
+	return "invalid client configuration: " + strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the problems matches target.
+func (e *ConfigError) Is(target error) bool {
+	for _, problem := range e.Problems {
+		if errors.Is(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTimeout configures a GrabExpress API client to bound each HTTP exchange, including reading the response
+// and fetching tokens. Retries get a fresh timeout.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		if d < 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidTimeout, d)
+		}
+		c.timeout = d
+		return nil
+	}
+}
+
+// validate checks the configuration gathered from the options and normalizes the URLs.
+func (c *Client) validate() []error {
+	var problems []error
+	if strings.TrimSpace(c.apiKey) == "" || strings.TrimSpace(c.secret) == "" {
+		problems = append(problems, ErrCredentialsMissing)
+	}
+	baseURL, err := normalizeURL("base URL", c.baseURL, ErrBaseURLMissing)
+	if err != nil {
+		problems = append(problems, err)
+	}
+	tokenURL, tokenErr := normalizeURL("token URL", c.tokenURL, ErrTokenURLMissing)
+	if tokenErr != nil {
+		problems = append(problems, tokenErr)
+	}
+	if err == nil && tokenErr == nil {
+		if err := checkEnvironment(baseURL, tokenURL); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	c.baseURL, c.tokenURL = baseURL, tokenURL
+	return problems
+}
+
+// normalizeURL checks that raw is an absolute http(s) URL, and strips its trailing slashes.
+func normalizeURL(name, raw string, missing error) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", missing
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw, fmt.Errorf("%w: %s: %v", ErrInvalidURL, name, err)
+	}
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		return raw, fmt.Errorf("%w: %s %q must use http or https", ErrInvalidURL, name, raw)
+	case u.Host == "":
+		return raw, fmt.Errorf("%w: %s %q has no host", ErrInvalidURL, name, raw)
+	case u.RawQuery != "" || u.Fragment != "":
+		return raw, fmt.Errorf("%w: %s %q must not have a query or fragment", ErrInvalidURL, name, raw)
+	}
+	return strings.TrimRight(raw, "/"), nil
+}
+
+// withTimeout returns a copy of hc bounded by timeout, leaving the caller's client untouched.
+func withTimeout(hc *http.Client, timeout time.Duration) *http.Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	bounded := *hc
+	bounded.Timeout = timeout
+	return &bounded
+}
+
+// Environment variables read by Config.ApplyEnv and NewClientFromEnv.
+const (
+	EnvAPIKey           = "GRABEXPRESS_API_KEY"
+	EnvSecret           = "GRABEXPRESS_SECRET"
+	EnvEnvironment      = "GRABEXPRESS_ENVIRONMENT"
+	EnvBaseURL          = "GRABEXPRESS_BASE_URL"
+	EnvTokenURL         = "GRABEXPRESS_TOKEN_URL"
+	EnvTimeout          = "GRABEXPRESS_TIMEOUT"
+	EnvRetryAttempts    = "GRABEXPRESS_RETRY_ATTEMPTS"
+	EnvValidateRequests = "GRABEXPRESS_VALIDATE_REQUESTS"
+)
+
+// Duration is a time.Duration written as a string such as "30s" in JSON and YAML.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config is the serializable configuration of a Client, e.g. for twelve-factor deployments.
+// BaseURL and TokenURL override the endpoints of Environment.
+type Config struct {
+	APIKey      string `json:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+	Secret      string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	BaseURL     string `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+	TokenURL    string `json:"tokenURL,omitempty" yaml:"tokenURL,omitempty"`
+	// Timeout bounds each HTTP exchange, see WithTimeout.
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// RetryAttempts enables DefaultRetryPolicy with that many attempts when above 1.
+	RetryAttempts    int  `json:"retryAttempts,omitempty" yaml:"retryAttempts,omitempty"`
+	ValidateRequests bool `json:"validateRequests,omitempty" yaml:"validateRequests,omitempty"`
+}
+
+// LoadConfig reads a JSON or YAML configuration file.
+func LoadConfig(path string) (*Config, error) {
+	bb, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	// YAML is a superset of JSON.
+	if err := yaml.Unmarshal(bb, cfg); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ApplyEnv overrides cfg with the GRABEXPRESS_* environment variables that are set and not empty.
+func (cfg *Config) ApplyEnv() error {
+	var problems []error
+	for env, field := range map[string]*string{
+		EnvAPIKey:      &cfg.APIKey,
+		EnvSecret:      &cfg.Secret,
+		EnvEnvironment: &cfg.Environment,
+		EnvBaseURL:     &cfg.BaseURL,
+		EnvTokenURL:    &cfg.TokenURL,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*field = v
+		}
+	}
+	if v := os.Getenv(EnvTimeout); v != "" {
+		if err := cfg.Timeout.UnmarshalText([]byte(v)); err != nil {
+			problems = append(problems, fmt.Errorf("%w: %s: %v", ErrInvalidTimeout, EnvTimeout, err))
+		}
+	}
+	if v := os.Getenv(EnvRetryAttempts); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%w: %s: %v", ErrInvalidRetryPolicy, EnvRetryAttempts, err))
+		}
+		cfg.RetryAttempts = n
+	}
+	if v := os.Getenv(EnvValidateRequests); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %v", EnvValidateRequests, err))
+		}
+		cfg.ValidateRequests = b
+	}
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// Endpoints returns the environment described by cfg: the named preset, if any, with the URL overrides applied.
+func (cfg Config) Endpoints() (Environment, error) {
+	var env Environment
+	if cfg.Environment != "" {
+		var err error
+		if env, err = LookupEnvironment(cfg.Environment); err != nil {
+			return env, err
+		}
+	}
+	if cfg.BaseURL != "" {
+		env.BaseURL = cfg.BaseURL
+	}
+	if cfg.TokenURL != "" {
+		env.TokenURL = cfg.TokenURL
+	}
+	return env, nil
+}
+
+// Options returns the client options described by cfg.
+func (cfg Config) Options() ([]ClientOption, error) {
+	env, err := cfg.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	options := []ClientOption{
+		WithAPIKey(cfg.APIKey),
+		WithSecret(cfg.Secret),
+		WithEnvironment(env),
+		WithTimeout(time.Duration(cfg.Timeout)),
+	}
+	if cfg.RetryAttempts > 1 {
+		policy := DefaultRetryPolicy
+		policy.MaxAttempts = cfg.RetryAttempts
+		options = append(options, WithRetryPolicy(policy))
+	}
+	if cfg.ValidateRequests {
+		options = append(options, WithRequestValidation())
+	}
+	return options, nil
+}
+
+// NewClientFromConfig constructs a new Client from cfg. Options are applied after the configuration.
+func NewClientFromConfig(cfg Config, options ...ClientOption) (*Client, error) {
+	defaults, err := cfg.Options()
+	if err != nil {
+		return nil, &ConfigError{Problems: []error{err}}
+	}
+	return NewClient(append(defaults, options...)...)
+}
+
+// NewClientFromEnv constructs a new Client configured by the GRABEXPRESS_* environment variables.
+// Options are applied after the environment.
+func NewClientFromEnv(options ...ClientOption) (*Client, error) {
+	var cfg Config
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(cfg, options...)
+}
@@ -0,0 +1,119 @@
+package grabexpress
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// defaultScopes are the OAuth scopes requested for the client credentials token.
+var defaultScopes = []string{"grab_express.partner_deliveries"}
+
+// Environment is a set of GrabExpress endpoints with the OAuth scopes they expect.
+// The endpoints are the same for every country; the country is carried by the requests.
+type Environment struct {
+	Name     string
+	BaseURL  string
+	TokenURL string
+	Scopes   []string
+}
+
+// Environment presets
+var (
+	Staging = Environment{
+		Name:     "staging",
+		BaseURL:  "https://partner-api.stg-myteksi.com/grab-express-sandbox",
+		TokenURL: "https://api.stg-myteksi.com/grabid/v1/oauth2/token",
+		Scopes:   defaultScopes,
+	}
+	Production = Environment{
+		Name:     "production",
+		BaseURL:  "https://partner-api.grab.com/grab-express",
+		TokenURL: "https://api.grab.com/grabid/v1/oauth2/token",
+		Scopes:   defaultScopes,
+	}
+)
+
+// localTokenPath is the path of the token endpoint of a local simulator, see package grabexpresstest.
+const localTokenPath = "/grabid/v1/oauth2/token"
+
+var (
+	ErrUnknownEnvironment  = errors.New("unknown environment")
+	ErrEnvironmentMismatch = errors.New("base URL and token URL belong to different environments")
+)
+
+// LocalEnvironment returns the environment of a simulator listening at baseURL, e.g. a grabexpresstest.Server.
+func LocalEnvironment(baseURL string) Environment {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return Environment{
+		Name:     "local",
+		BaseURL:  baseURL,
+		TokenURL: baseURL + localTokenPath,
+		Scopes:   defaultScopes,
+	}
+}
+
+// LookupEnvironment returns the preset called name: "staging" or "production".
+func LookupEnvironment(name string) (Environment, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case Staging.Name, "sandbox":
+		return Staging, nil
+	case Production.Name, "prod":
+		return Production, nil
+	}
+	return Environment{}, fmt.Errorf("%w: %q", ErrUnknownEnvironment, name)
+}
+
+// WithEnvironment configures a GrabExpress API client with the base URL, token URL and scopes of env.
+// Options applied afterwards may still override the URLs, as long as they stay within the same environment.
+func WithEnvironment(env Environment) ClientOption {
+	return func(c *Client) error {
+		c.baseURL = env.BaseURL
+		c.tokenURL = env.TokenURL
+		if len(env.Scopes) > 0 {
+			c.scopes = env.Scopes
+		}
+		return nil
+	}
+}
+
+// checkEnvironment fails when the base URL and the token URL are known to belong to different environments,
+// e.g. a staging token used against production, which would only fail later with a 401.
+func checkEnvironment(baseURL, tokenURL string) error {
+	base, token := environmentOf(baseURL), environmentOf(tokenURL)
+	if base != "" && token != "" && base != token {
+		return fmt.Errorf("%w: base URL is %s, token URL is %s", ErrEnvironmentMismatch, base, token)
+	}
+	return nil
+}
+
+// environmentOf returns the name of the environment rawURL belongs to, or "" when it is not a known host.
+func environmentOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	host := u.Hostname()
+	for _, env := range []Environment{Staging, Production} {
+		if host == hostname(env.BaseURL) || host == hostname(env.TokenURL) {
+			return env.Name
+		}
+	}
+	if host == "localhost" {
+		return "local"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return "local"
+	}
+	return ""
+}
+
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -1,8 +1,15 @@
 package grabexpress
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
 )
 
 var (
@@ -12,22 +19,209 @@ var (
 	ErrTokenURLMissing     = errors.New("token URL missing")
 )
 
+// ErrDeliveryInFlight is returned by CreateDelivery when an earlier creation with the same idempotency key
+// has an unknown outcome, e.g. after a timeout. Unless a DeliveryLookupFunc or WithInFlightTimeout resolves it,
+// check whether the delivery exists, then call Complete on the IdempotencyStore with its delivery ID, or Release.
+var ErrDeliveryInFlight = errors.New("delivery creation with the same idempotency key is still in flight")
+
+// Error categories, matched by *Error through errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrValidation   = errors.New("validation failed")
+	ErrCannotCancel = errors.New("delivery cannot be canceled")
+	ErrServer       = errors.New("server error")
+	ErrTransport    = errors.New("transport error")
+)
+
+// FieldError is a validation error on a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 // Error is the conventional GrabExpress client error
 type Error struct {
-	Status    int    `json:"status,omitempty"`
-	Message   string `json:"message,omitempty"`
-	RequestID string `json:"requestID,omitempty"`
+	// Status is the HTTP status of the API response, or 0 when no response was received.
+	Status    int          `json:"status,omitempty"`
+	Code      string       `json:"code,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	Details   string       `json:"details,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"requestID,omitempty"`
+	// RetryAfter is the delay requested by the API through the Retry-After header.
+	RetryAfter time.Duration `json:"retryAfter,omitempty"`
+	// Err is the underlying cause, if any.
+	Err error `json:"-"`
+
+	method    string
+	transport bool
+	auth      bool
+	retryable bool
 }
 
 // Error returns error message.
 // This enables grabexpress.Error to comply with Go error interface
 func (e *Error) Error() string {
-	return e.Message
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	if e.Code != "" {
+		msg = e.Code + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches e against the error categories, e.g. errors.Is(err, grabexpress.ErrNotFound).
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	case ErrValidation:
+		return !e.isCancel() && (e.Status == http.StatusBadRequest || e.Status == http.StatusUnprocessableEntity)
+	case ErrCannotCancel:
+		return e.isCancel() && (e.Status == http.StatusBadRequest || e.Status == http.StatusConflict ||
+			e.Status == http.StatusUnprocessableEntity)
+	case ErrServer:
+		return e.Status >= http.StatusInternalServerError
+	case ErrTransport:
+		return e.transport
+	case ErrAuthenticationError:
+		return e.auth
+	}
+	return false
+}
+
+// Retryable reports whether the request may succeed if sent again unchanged: transient network failures,
+// rate limiting and temporarily unavailable servers.
+func (e *Error) Retryable() bool {
+	if e.transport {
+		return e.retryable
+	}
+	return isRetryableStatus(e.Status)
+}
+
+// Temporary reports whether the error is caused by a transient condition, including server errors
+// that may not be safe to retry blindly.
+func (e *Error) Temporary() bool {
+	return e.Retryable() || e.Status >= http.StatusInternalServerError || e.Status == http.StatusRequestTimeout
 }
 
+func (e *Error) isCancel() bool {
+	return e.method == http.MethodDelete
+}
+
+// wrapError wraps a local error, e.g. a request that could not be encoded.
 func wrapError(err error) *Error {
+	if apiErr, ok := err.(*Error); ok {
+		return apiErr
+	}
 	return &Error{
-		Status:  http.StatusInternalServerError,
 		Message: err.Error(),
+		Err:     err,
+	}
+}
+
+// newTransportError wraps an error that prevented receiving a response.
+func newTransportError(ctx context.Context, err error) *Error {
+	return &Error{
+		Message:   err.Error(),
+		Err:       err,
+		transport: true,
+		retryable: isRetryableTransportError(ctx, err),
+	}
+}
+
+// newAuthError wraps an error returned while fetching an access token.
+func newAuthError(ctx context.Context, err error) *Error {
+	e := &Error{
+		Message: ErrAuthenticationError.Error() + ": " + err.Error(),
+		Err:     err,
+		auth:    true,
+	}
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+		e.Status = retrieveErr.Response.StatusCode
+	} else if ctx.Err() == nil {
+		// The token endpoint could not be reached.
+		e.transport = true
+		e.retryable = true
+	}
+	return e
+}
+
+// errorBody is the error payload of the GrabExpress APIs.
+type errorBody struct {
+	Code       json.RawMessage `json:"code"`
+	Message    string          `json:"message"`
+	Reason     string          `json:"reason"`
+	DevMessage string          `json:"devMessage"`
+	Arg        string          `json:"arg"`
+	Details    json.RawMessage `json:"details"`
+	Errors     []FieldError    `json:"errors"`
+	Fields     []FieldError    `json:"fields"`
+}
+
+// newResponseError builds the error describing an unsuccessful API response.
+func newResponseError(resp *http.Response, requestID string) *Error {
+	e := &Error{
+		Status:     resp.StatusCode,
+		RequestID:  requestID,
+		RetryAfter: retryAfter(resp.Header, time.Now()),
+	}
+	if resp.Request != nil {
+		e.method = resp.Request.Method
+	}
+	if resp.ContentLength == 0 {
+		return e
+	}
+	bb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		e.Err = err
+		return e
+	}
+	var body errorBody
+	if err := json.Unmarshal(bb, &body); err != nil {
+		e.Message = strings.TrimSpace(string(bb))
+		return e
+	}
+	if len(body.Code) > 0 && string(body.Code) != "null" {
+		e.Code = strings.Trim(string(body.Code), `"`)
+	}
+	e.Message = firstNonEmpty(body.Message, body.DevMessage, body.Reason)
+	if len(body.Details) > 0 && string(body.Details) != "null" {
+		var details string
+		if json.Unmarshal(body.Details, &details) != nil {
+			details = string(body.Details)
+		}
+		e.Details = details
+	}
+	e.Fields = append(body.Errors, body.Fields...)
+	if len(e.Fields) == 0 && body.Arg != "" {
+		e.Fields = []FieldError{{Field: body.Arg, Message: e.Message}}
+	}
+	return e
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
 	}
+	return ""
 }
@@ -0,0 +1,58 @@
+package grabexpress
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flightTimeout bounds a shared execution, which no single caller can cancel.
+const flightTimeout = time.Minute
+
+// flightGroup collapses concurrent calls sharing a key into a single execution.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// do runs fn once for all the concurrent callers with the same key. shared reports whether the result
+// comes from another caller's execution. fn runs under a context carrying the values of the first caller's ctx,
+// but not its cancellation, so that a caller giving up does not fail the others. Each caller stops waiting
+// when its own ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (value interface{}, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall{}
+	}
+	call, shared := g.calls[key]
+	if !shared {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(ctx, key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, shared, call.err
+	case <-ctx.Done():
+		return nil, shared, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, call *flightCall, fn func(ctx context.Context) (interface{}, error)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flightTimeout)
+	defer cancel()
+	call.value, call.err = fn(ctx)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+}
@@ -2,11 +2,14 @@ package grabexpress
 
 // Country ...
 type Country struct {
-	Name       string
-	Code       CountryCode
-	Cities     []CityCode
-	Locales    []Locale
+	Name    string
+	Code    CountryCode
+	Cities  []CityCode
+	Locales []Locale
+	// PhoneRegex matches phone numbers in the national format expected by GrabExpress. It is RE2 syntax.
 	PhoneRegex string
+	// CallingCode is the ITU-T E.164 country calling code, without the leading +.
+	CallingCode string
 }
 
 // CityCode is the UN/LOCODE of supported cities.
@@ -114,81 +117,92 @@ var (
 		Name: "Unknown",
 	}
 	CountryBrasil = Country{
-		Name:       "Brasil",
-		Code:       CountryCodeBrasil,
-		Cities:     []CityCode{CityCodeBrasilSaoPaulo, CityCodeBrasilRioDeJaneiro},
-		PhoneRegex: "^[0-9]{2}[9]{1}[0-9]{8}$",
-		Locales:    []Locale{LocaleBrasilEN, LocaleBrasilPT},
+		Name:        "Brasil",
+		Code:        CountryCodeBrasil,
+		Cities:      []CityCode{CityCodeBrasilSaoPaulo, CityCodeBrasilRioDeJaneiro},
+		PhoneRegex:  "^[0-9]{2}[9]{1}[0-9]{8}$",
+		Locales:     []Locale{LocaleBrasilEN, LocaleBrasilPT},
+		CallingCode: "55",
 	}
 	CountryHongKong = Country{
-		Name:       "Hong Kong",
-		Code:       CountryCodeHongKong,
-		Cities:     []CityCode{CityCodeHongKongHongKong},
-		PhoneRegex: "^((?!999)([2-9][0-9]{7}))$",
-		Locales:    []Locale{LocaleHongKongEN, LocaleHongKongZH},
+		Name:        "Hong Kong",
+		Code:        CountryCodeHongKong,
+		Cities:      []CityCode{CityCodeHongKongHongKong},
+		PhoneRegex:  "^([2-8][0-9]{7}|9([0-8][0-9]|9[0-8])[0-9]{5})$",
+		Locales:     []Locale{LocaleHongKongEN, LocaleHongKongZH},
+		CallingCode: "852",
 	}
 	CountryIndia = Country{
-		Name:       "India",
-		Code:       CountryCodeIndia,
-		Cities:     []CityCode{CityCodeIndiaBengaluru, CityCodeIndiaMumbai, CityCodeIndiaDelhi},
-		PhoneRegex: "^([6-9][0-9]{9}|22[0-9]{8})$",
-		Locales:    []Locale{LocaleIndiaEN, LocaleIndiaHI, LocaleIndiaKN, LocaleIndiaMR},
+		Name:        "India",
+		Code:        CountryCodeIndia,
+		Cities:      []CityCode{CityCodeIndiaBengaluru, CityCodeIndiaMumbai, CityCodeIndiaDelhi},
+		PhoneRegex:  "^([6-9][0-9]{9}|22[0-9]{8})$",
+		Locales:     []Locale{LocaleIndiaEN, LocaleIndiaHI, LocaleIndiaKN, LocaleIndiaMR},
+		CallingCode: "91",
 	}
 	CountryIndonesia = Country{
-		Name:       "Indonesia",
-		Code:       CountryCodeIndonesia,
-		Cities:     []CityCode{CityCodeIndonesiaJakarata},
-		PhoneRegex: "^0(8\\d{8,11}|21\\d{7,8})$",
-		Locales:    []Locale{LocaleIndonesiaEN, LocaleIndonesiaID},
+		Name:        "Indonesia",
+		Code:        CountryCodeIndonesia,
+		Cities:      []CityCode{CityCodeIndonesiaJakarata},
+		PhoneRegex:  "^0(8\\d{8,11}|21\\d{7,8})$",
+		Locales:     []Locale{LocaleIndonesiaEN, LocaleIndonesiaID},
+		CallingCode: "62",
 	}
 	CountryMalaysia = Country{
-		Name:       "Malaysia",
-		Code:       CountryCodeMalaysia,
-		Cities:     []CityCode{CityCodeMalaysiaKualaLumpur},
-		PhoneRegex: "^0(1[1,5]?\\d{8}|[4-7,9]\\d{7}|8[2-9]\\d{6}|3\\d{8})$",
-		Locales:    []Locale{LocaleMalaysiaEN, LocaleMalaysiaMS},
+		Name:        "Malaysia",
+		Code:        CountryCodeMalaysia,
+		Cities:      []CityCode{CityCodeMalaysiaKualaLumpur},
+		PhoneRegex:  "^0(1[1,5]?\\d{8}|[4-7,9]\\d{7}|8[2-9]\\d{6}|3\\d{8})$",
+		Locales:     []Locale{LocaleMalaysiaEN, LocaleMalaysiaMS},
+		CallingCode: "60",
 	}
 	CountryMexico = Country{
-		Name:       "Mexico",
-		Code:       CountryCodeMexico,
-		Cities:     []CityCode{CityCodeMexicoMexico},
-		PhoneRegex: "^([+]+52?)?(\\d{3}?){2}\\d{4}$",
-		Locales:    []Locale{LocaleMexicoEN, LocaleMexicoMX},
+		Name:        "Mexico",
+		Code:        CountryCodeMexico,
+		Cities:      []CityCode{CityCodeMexicoMexico},
+		PhoneRegex:  "^([+]+52?)?(\\d{3}?){2}\\d{4}$",
+		Locales:     []Locale{LocaleMexicoEN, LocaleMexicoMX},
+		CallingCode: "52",
 	}
 	CountryPhilippines = Country{
-		Name:       "Philippines",
-		Code:       CountryCodePhilippines,
-		Cities:     []CityCode{CityCodePhilippinesManila, CityCodePhilippinesCebu},
-		PhoneRegex: "^09[0-9]{9}$|^0?2[0-9]{7}$|^0?32[0-9]{7}$",
-		Locales:    []Locale{LocalePhilippinesEN},
+		Name:        "Philippines",
+		Code:        CountryCodePhilippines,
+		Cities:      []CityCode{CityCodePhilippinesManila, CityCodePhilippinesCebu},
+		PhoneRegex:  "^09[0-9]{9}$|^0?2[0-9]{7}$|^0?32[0-9]{7}$",
+		Locales:     []Locale{LocalePhilippinesEN},
+		CallingCode: "63",
 	}
 	CountrySingapore = Country{
-		Name:       "Singapore",
-		Code:       CountryCodeSingapore,
-		Cities:     []CityCode{CityCodeSingaporeSingapore},
-		PhoneRegex: "^[689]{1}[0-9]{7}$",
-		Locales:    []Locale{LocaleSingaporeEN},
+		Name:        "Singapore",
+		Code:        CountryCodeSingapore,
+		Cities:      []CityCode{CityCodeSingaporeSingapore},
+		PhoneRegex:  "^[689]{1}[0-9]{7}$",
+		Locales:     []Locale{LocaleSingaporeEN},
+		CallingCode: "65",
 	}
 	CountryTaiwan = Country{
-		Name:       "Taiwan",
-		Code:       CountryCodeTaiwan,
-		Cities:     []CityCode{CityCodeTaiwanTaipei},
-		PhoneRegex: "^0([1-8]{1}[0-9]{7,8}|9[0-9]{8})$",
-		Locales:    []Locale{LocaleTaiwanZH},
+		Name:        "Taiwan",
+		Code:        CountryCodeTaiwan,
+		Cities:      []CityCode{CityCodeTaiwanTaipei},
+		PhoneRegex:  "^0([1-8]{1}[0-9]{7,8}|9[0-9]{8})$",
+		Locales:     []Locale{LocaleTaiwanZH},
+		CallingCode: "886",
 	}
 	CountryThailand = Country{
-		Name:       "Thailand",
-		Code:       CountryCodeThailand,
-		Cities:     []CityCode{CityCodeThailandBangkok, CityCodeThailandPattaya},
-		PhoneRegex: "^(0[0-9]{8,9}|[0-9]{4})$",
-		Locales:    []Locale{LocaleThailandEN, LocaleThailandTH},
+		Name:        "Thailand",
+		Code:        CountryCodeThailand,
+		Cities:      []CityCode{CityCodeThailandBangkok, CityCodeThailandPattaya},
+		PhoneRegex:  "^(0[0-9]{8,9}|[0-9]{4})$",
+		Locales:     []Locale{LocaleThailandEN, LocaleThailandTH},
+		CallingCode: "66",
 	}
 	CountryVietnam = Country{
-		Name:       "Vietnam",
-		Code:       CountryCodeVietnam,
-		Cities:     []CityCode{CityCodeVietnamHoChiMinh, CityCodeVietnamHanoi},
-		PhoneRegex: "^0?(2|[35789])[0-9]{8}$|^02[48][0-9]{8}$",
-		Locales:    []Locale{LocaleVietnamEN, LocaleVietnamVI},
+		Name:        "Vietnam",
+		Code:        CountryCodeVietnam,
+		Cities:      []CityCode{CityCodeVietnamHoChiMinh, CityCodeVietnamHanoi},
+		PhoneRegex:  "^0?(2|[35789])[0-9]{8}$|^02[48][0-9]{8}$",
+		Locales:     []Locale{LocaleVietnamEN, LocaleVietnamVI},
+		CallingCode: "84",
 	}
 )
 
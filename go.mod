@@ -1,5 +1,16 @@
 module github.com/rgaquino/grabexpress-go
 
-go 1.15
+go 1.21
 
-require golang.org/x/oauth2 v0.0.0-20210113205817-d3ed898aa8a3
+require (
+	golang.org/x/oauth2 v0.0.0-20210113205817-d3ed898aa8a3
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	golang.org/x/net v0.0.0-20200822124328-c89045814202 // indirect
+	google.golang.org/appengine v1.6.6 // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+)
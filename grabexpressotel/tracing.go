@@ -0,0 +1,198 @@
+// Package grabexpressotel traces GrabExpress API clients with OpenTelemetry. API calls and token fetches are
+// recorded as client spans, and the trace context is propagated to the API.
+//
+//	client, err := grabexpress.NewClient(..., grabexpressotel.WithTracing(
+//		grabexpressotel.WithTracerProvider(tp),
+//	))
+package grabexpressotel
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+)
+
+// instrumentationName identifies the spans of this package.
+const instrumentationName = "github.com/rgaquino/grabexpress-go/grabexpressotel"
+
+// requestIDHeader carries the ID the API assigned to a request.
+const requestIDHeader = "X-Grabkit-Grab-Requestid"
+
+// Span attributes
+const (
+	AttributeOperation       = attribute.Key("grabexpress.operation")
+	AttributeEndpoint        = attribute.Key("grabexpress.endpoint")
+	AttributeRequestID       = attribute.Key("grabexpress.request_id")
+	AttributeMerchantOrderID = attribute.Key("grabexpress.merchant_order_id")
+	AttributeDeliveryID      = attribute.Key("grabexpress.delivery_id")
+	AttributeAttempts        = attribute.Key("grabexpress.attempts")
+)
+
+// Option is the type of options for WithTracing(...), Middleware(...) and TokenMiddleware(...).
+type Option func(*tracing)
+
+// WithTracerProvider configures the tracer provider spans are recorded with.
+// Defaults to the global tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(t *tracing) {
+		t.provider = tp
+	}
+}
+
+// WithPropagator configures how the trace context is injected into requests.
+// Defaults to the W3C Trace Context format.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(t *tracing) {
+		t.propagator = p
+	}
+}
+
+// WithTracing configures a GrabExpress API client to trace its API calls and token fetches.
+// Register it before other middlewares so that their work is part of the call spans.
+func WithTracing(options ...Option) grabexpress.ClientOption {
+	t := newTracing(options)
+	withMiddleware := grabexpress.WithMiddleware(t.middleware)
+	withTokenMiddleware := grabexpress.WithTokenMiddleware(t.tokenMiddleware)
+	return func(c *grabexpress.Client) error {
+		if err := withMiddleware(c); err != nil {
+			return err
+		}
+		return withTokenMiddleware(c)
+	}
+}
+
+// Middleware returns a grabexpress.Middleware tracing API calls.
+func Middleware(options ...Option) grabexpress.Middleware {
+	return newTracing(options).middleware
+}
+
+// TokenMiddleware returns a grabexpress.TokenMiddleware tracing token fetches.
+func TokenMiddleware(options ...Option) grabexpress.TokenMiddleware {
+	return newTracing(options).tokenMiddleware
+}
+
+// tracing holds the instrumentation of a client.
+type tracing struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	tracer     trace.Tracer
+}
+
+func newTracing(options []Option) *tracing {
+	t := &tracing{}
+	for _, option := range options {
+		option(t)
+	}
+	if t.provider == nil {
+		t.provider = otel.GetTracerProvider()
+	}
+	if t.propagator == nil {
+		t.propagator = propagation.TraceContext{}
+	}
+	t.tracer = t.provider.Tracer(instrumentationName)
+	return t
+}
+
+// middleware traces an API round-trip, and propagates its span to the API.
+func (t *tracing) middleware(next grabexpress.RoundTripFunc) grabexpress.RoundTripFunc {
+	return func(ctx context.Context, call *grabexpress.Call) error {
+		ctx, span := t.tracer.Start(ctx, "grabexpress."+call.Operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				AttributeOperation.String(call.Operation),
+				AttributeEndpoint.String(string(call.Endpoint)),
+				semconv.HTTPRequestMethodKey.String(call.Method),
+				semconv.URLPath(call.Path),
+			))
+		defer span.End()
+		t.propagator.Inject(ctx, propagation.HeaderCarrier(call.Header))
+
+		err := next(ctx, call)
+		span.SetAttributes(callAttributes(call)...)
+		recordError(span, err)
+		return err
+	}
+}
+
+// tokenMiddleware traces an OAuth token fetch, made over a copy of hc propagating the span.
+func (t *tracing) tokenMiddleware(next grabexpress.TokenFetchFunc) grabexpress.TokenFetchFunc {
+	return func(ctx context.Context, hc *http.Client) (*oauth2.Token, error) {
+		ctx, span := t.tracer.Start(ctx, "grabexpress.FetchToken", trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+		token, err := next(ctx, t.httpClient(hc))
+		recordError(span, err)
+		return token, err
+	}
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// callAttributes describes the outcome of a call.
+func callAttributes(call *grabexpress.Call) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{AttributeAttempts.Int(call.Attempts)}
+	if call.Status != 0 {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(call.Status))
+	}
+	if id := call.ResponseHeader.Get(requestIDHeader); id != "" {
+		attrs = append(attrs, AttributeRequestID.String(id))
+	}
+	merchantOrderID, deliveryID := "", ""
+	if req, ok := call.Request.(*grabexpress.CreateDeliveryRequest); ok && req != nil {
+		merchantOrderID = req.MerchantOrderID
+	}
+	switch resp := call.Response.(type) {
+	case *grabexpress.CreateDeliveryResponse:
+		deliveryID = resp.DeliveryID
+	case *grabexpress.GetDeliveryResponse:
+		merchantOrderID, deliveryID = resp.MerchantOrderID, resp.DeliveryID
+	}
+	if deliveryID == "" && call.Endpoint == grabexpress.EndpointDeliveries && call.Method != http.MethodPost {
+		deliveryID = call.Path[strings.LastIndex(call.Path, "/")+1:]
+	}
+	if merchantOrderID != "" {
+		attrs = append(attrs, AttributeMerchantOrderID.String(merchantOrderID))
+	}
+	if deliveryID != "" {
+		attrs = append(attrs, AttributeDeliveryID.String(deliveryID))
+	}
+	return attrs
+}
+
+// httpClient returns a copy of hc that propagates the trace context of its requests.
+func (t *tracing) httpClient(hc *http.Client) *http.Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	propagating := *hc
+	propagating.Transport = &propagatingTransport{base: hc.Transport, propagator: t.propagator}
+	return &propagating
+}
+
+type propagatingTransport struct {
+	base       http.RoundTripper
+	propagator propagation.TextMapPropagator
+}
+
+func (t *propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	req = req.Clone(req.Context())
+	t.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,87 @@
+package grabexpressotel_test
+
+import (
+	"context"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpressotel"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client(grabexpressotel.WithTracing(grabexpressotel.WithTracerProvider(tp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.CreateDelivery(context.Background(), &grabexpress.CreateDeliveryRequest{
+		MerchantOrderID: "ORDER-1",
+		ServiceType:     grabexpress.ServiceTypeInstant,
+		Packages:        []grabexpress.Package{{Name: "Documents", Quantity: 1, Dimensions: grabexpress.Dimensions{Weight: 100}}},
+		Origin:          grabexpress.Waypoint{Address: "1 Raffles Place", Coordinates: grabexpress.Coordinates{Latitude: 1.2840, Longitude: 103.8514}},
+		Destination:     grabexpress.Waypoint{Address: "10 Bayfront Avenue", Coordinates: grabexpress.Coordinates{Latitude: 1.2834, Longitude: 103.8607}},
+		Sender:          grabexpress.Contact{FirstName: "Sam", Phone: "91234567"},
+		Recipient:       grabexpress.Contact{FirstName: "Riley", Phone: "98765432"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	fetch, ok := spans["grabexpress.FetchToken"]
+	if !ok {
+		t.Fatalf("spans = %v, want a token fetch", spans)
+	}
+	call, ok := spans["grabexpress.CreateDelivery"]
+	if !ok {
+		t.Fatalf("spans = %v, want a CreateDelivery call", spans)
+	}
+	if call.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span kind = %v, want client", call.SpanKind())
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range call.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	for key, want := range map[attribute.Key]string{
+		grabexpressotel.AttributeOperation:       "CreateDelivery",
+		grabexpressotel.AttributeEndpoint:        string(grabexpress.EndpointDeliveries),
+		grabexpressotel.AttributeMerchantOrderID: "ORDER-1",
+		grabexpressotel.AttributeDeliveryID:      resp.DeliveryID,
+	} {
+		if got := attrs[key].Emit(); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	// Both the token fetch and the call propagate their own span to the server.
+	parents := map[string]trace.SpanContext{
+		"/grabid/v1/oauth2/token": fetch.SpanContext(),
+		"/v1/deliveries":          call.SpanContext(),
+	}
+	for _, r := range srv.Requests() {
+		parent, ok := parents[r.Path]
+		if !ok {
+			continue
+		}
+		if got := r.Header.Get("Traceparent"); got == "" || !isTraceparent(got, parent) {
+			t.Errorf("%s traceparent = %q, want span %s", r.Path, got, parent.SpanID())
+		}
+	}
+}
+
+// isTraceparent reports whether traceparent carries sc, sampled.
+func isTraceparent(traceparent string, sc trace.SpanContext) bool {
+	return traceparent == "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01"
+}
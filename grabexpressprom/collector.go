@@ -0,0 +1,94 @@
+// Package grabexpressprom exports the metrics of GrabExpress API clients to Prometheus.
+//
+//	metrics := grabexpress.NewMetrics()
+//	client, err := grabexpress.NewClient(..., grabexpress.WithMetrics(metrics))
+//	prometheus.MustRegister(grabexpressprom.NewCollector(metrics))
+package grabexpressprom
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+const namespace = "grabexpress"
+
+var (
+	requestsDesc = prometheus.NewDesc(namespace+"_requests_total",
+		"Completed GrabExpress API calls.", []string{"endpoint", "operation"}, nil)
+	errorsDesc = prometheus.NewDesc(namespace+"_errors_total",
+		"Failed GrabExpress API calls, by error category.", []string{"endpoint", "operation", "category"}, nil)
+	retriesDesc = prometheus.NewDesc(namespace+"_retries_total",
+		"Retried attempts of GrabExpress API calls.", []string{"endpoint", "operation"}, nil)
+	inFlightDesc = prometheus.NewDesc(namespace+"_in_flight_requests",
+		"GrabExpress API calls in progress.", []string{"endpoint", "operation"}, nil)
+	durationDesc = prometheus.NewDesc(namespace+"_request_duration_seconds",
+		"Duration of GrabExpress API calls, including retries.", []string{"endpoint", "operation"}, nil)
+	rateLimitWaitsDesc = prometheus.NewDesc(namespace+"_rate_limit_waits_total",
+		"GrabExpress API calls held back by the client-side rate limiter.", []string{"endpoint", "operation"}, nil)
+	rateLimitWaitSecondsDesc = prometheus.NewDesc(namespace+"_rate_limit_wait_seconds_total",
+		"Time spent waiting for the client-side rate limiter.", []string{"endpoint", "operation"}, nil)
+	tokenRefreshesDesc = prometheus.NewDesc(namespace+"_token_refreshes_total",
+		"OAuth access token fetches.", nil, nil)
+	tokenRefreshErrorsDesc = prometheus.NewDesc(namespace+"_token_refresh_errors_total",
+		"Failed OAuth access token fetches.", nil, nil)
+)
+
+// Collector is a prometheus.Collector reading a grabexpress.Metrics.
+type Collector struct {
+	metrics *grabexpress.Metrics
+}
+
+// NewCollector constructs a Collector exporting metrics.
+func NewCollector(metrics *grabexpress.Metrics) *Collector {
+	return &Collector{metrics: metrics}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		requestsDesc, errorsDesc, retriesDesc, inFlightDesc, durationDesc,
+		rateLimitWaitsDesc, rateLimitWaitSecondsDesc, tokenRefreshesDesc, tokenRefreshErrorsDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := c.metrics.Snapshot()
+	ch <- prometheus.MustNewConstMetric(tokenRefreshesDesc, prometheus.CounterValue, float64(snapshot.TokenRefreshes))
+	ch <- prometheus.MustNewConstMetric(tokenRefreshErrorsDesc, prometheus.CounterValue, float64(snapshot.TokenRefreshErrors))
+
+	for endpoint, operations := range snapshot.Endpoints {
+		for operation, s := range operations {
+			collectOperation(ch, string(endpoint), operation, s)
+		}
+	}
+}
+
+// collectOperation sends the metrics of the operation calls to endpoint.
+func collectOperation(ch chan<- prometheus.Metric, endpoint, operation string, s grabexpress.EndpointSnapshot) {
+	labels := []string{endpoint, operation}
+	ch <- prometheus.MustNewConstMetric(requestsDesc, prometheus.CounterValue, float64(s.Requests), labels...)
+	ch <- prometheus.MustNewConstMetric(retriesDesc, prometheus.CounterValue, float64(s.Retries), labels...)
+	ch <- prometheus.MustNewConstMetric(inFlightDesc, prometheus.GaugeValue, float64(s.InFlight), labels...)
+	ch <- prometheus.MustNewConstMetric(rateLimitWaitsDesc, prometheus.CounterValue, float64(s.RateLimitWaits), labels...)
+	ch <- prometheus.MustNewConstMetric(rateLimitWaitSecondsDesc, prometheus.CounterValue, s.RateLimitWaitSeconds, labels...)
+
+	categories := make([]string, 0, len(s.Errors))
+	for category := range s.Errors {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		ch <- prometheus.MustNewConstMetric(errorsDesc, prometheus.CounterValue, float64(s.Errors[category]), endpoint, operation, category)
+	}
+
+	buckets := make(map[float64]uint64, len(s.Latency.Buckets))
+	for i, upper := range s.Latency.Buckets {
+		buckets[upper] = s.Latency.Counts[i]
+	}
+	ch <- prometheus.MustNewConstHistogram(durationDesc, s.Latency.Count, s.Latency.Sum, buckets, labels...)
+}
@@ -0,0 +1,39 @@
+package grabexpressprom_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpressprom"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+func TestCollector(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	metrics := grabexpress.NewMetrics()
+	client, err := srv.Client(grabexpress.WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetDelivery(context.Background(), "MISSING"); err == nil {
+		t.Fatal("GetDelivery() of a missing delivery succeeded")
+	}
+
+	expected := `
+# HELP grabexpress_errors_total Failed GrabExpress API calls, by error category.
+# TYPE grabexpress_errors_total counter
+grabexpress_errors_total{category="not_found",endpoint="deliveries",operation="GetDelivery"} 1
+# HELP grabexpress_requests_total Completed GrabExpress API calls.
+# TYPE grabexpress_requests_total counter
+grabexpress_requests_total{endpoint="deliveries",operation="GetDelivery"} 1
+`
+	err = testutil.CollectAndCompare(grabexpressprom.NewCollector(metrics), strings.NewReader(expected),
+		"grabexpress_errors_total", "grabexpress_requests_total")
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,259 @@
+package grabexpresstest
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+// Pricing of the simulated services, in major currency units scaled by the currency of the origin city.
+var services = []struct {
+	service grabexpress.Service
+	base    float64
+	perKm   float64
+	speed   float64 // km per minute
+}{
+	{grabexpress.Service{ID: 1, Type: grabexpress.ServiceTypeInstant, Name: "GrabExpress Instant"}, 5, 1, 0.5},
+	{grabexpress.Service{ID: 2, Type: grabexpress.ServiceTypeSameDay, Name: "GrabExpress Same Day"}, 3, 0.6, 0.2},
+	{grabexpress.Service{ID: 3, Type: grabexpress.ServiceTypeBulk, Name: "GrabExpress Bulk"}, 8, 1.2, 0.4},
+}
+
+// currencies of the simulated countries, with how many local units a simulated unit of price is worth.
+var currencies = map[grabexpress.CountryCode]struct {
+	currency grabexpress.Currency
+	scale    float64
+}{
+	grabexpress.CountryCodeBrasil:      {grabexpress.Currency{Code: "BRL", Symbol: "R$", Exponent: 2}, 5},
+	grabexpress.CountryCodeHongKong:    {grabexpress.Currency{Code: "HKD", Symbol: "HK$", Exponent: 2}, 8},
+	grabexpress.CountryCodeIndia:       {grabexpress.Currency{Code: "INR", Symbol: "₹", Exponent: 2}, 80},
+	grabexpress.CountryCodeIndonesia:   {grabexpress.Currency{Code: "IDR", Symbol: "Rp", Exponent: 0}, 15000},
+	grabexpress.CountryCodeMalaysia:    {grabexpress.Currency{Code: "MYR", Symbol: "RM", Exponent: 2}, 4},
+	grabexpress.CountryCodeMexico:      {grabexpress.Currency{Code: "MXN", Symbol: "$", Exponent: 2}, 18},
+	grabexpress.CountryCodePhilippines: {grabexpress.Currency{Code: "PHP", Symbol: "₱", Exponent: 2}, 55},
+	grabexpress.CountryCodeSingapore:   {grabexpress.Currency{Code: "SGD", Symbol: "S$", Exponent: 2}, 1},
+	grabexpress.CountryCodeTaiwan:      {grabexpress.Currency{Code: "TWD", Symbol: "NT$", Exponent: 2}, 30},
+	grabexpress.CountryCodeThailand:    {grabexpress.Currency{Code: "THB", Symbol: "฿", Exponent: 2}, 35},
+	grabexpress.CountryCodeVietnam:     {grabexpress.Currency{Code: "VND", Symbol: "₫", Exponent: 0}, 24000},
+}
+
+// pickupDelay is how long after creation a simulated courier picks the packages up.
+const pickupDelay = 10 * time.Minute
+
+type delivery struct {
+	id          string
+	req         grabexpress.CreateDeliveryRequest
+	quote       grabexpress.QuoteBase
+	createdAt   time.Time
+	trackingURL string
+	pickupPin   string
+
+	endStatus grabexpress.OrderStatus
+	endedAt   time.Time
+	reason    string
+}
+
+func newDelivery(id string, req *grabexpress.CreateDeliveryRequest, q grabexpress.QuoteBase, now time.Time, baseURL string) *delivery {
+	return &delivery{
+		id:          id,
+		req:         *req,
+		quote:       q,
+		createdAt:   now,
+		trackingURL: fmt.Sprintf("%s/track/%s", baseURL, id),
+		pickupPin:   fmt.Sprintf("%04d", now.UnixNano()%10000),
+	}
+}
+
+// end moves the delivery into a terminal status, unless it already is in one.
+func (d *delivery) end(status grabexpress.OrderStatus, now time.Time, reason string) {
+	if d.endStatus != "" {
+		return
+	}
+	d.endStatus = status
+	d.endedAt = now
+	d.reason = reason
+}
+
+// snapshot returns the state of the delivery at now.
+func (d *delivery) snapshot(now time.Time, lifecycle []Step) grabexpress.Delivery {
+	at := now
+	if d.endStatus != "" && d.endedAt.Before(at) {
+		at = d.endedAt
+	}
+	timeline := &grabexpress.Timeline{}
+	status := grabexpress.OrderStatusQueueing
+	var courierSince time.Time
+	for _, step := range lifecycle {
+		reached := d.createdAt.Add(step.After)
+		if reached.After(at) {
+			break
+		}
+		status = step.Status
+		t := reached
+		switch step.Status {
+		case grabexpress.OrderStatusQueueing:
+			timeline.Create = &t
+		case grabexpress.OrderStatusPickingUp:
+			timeline.Allocate = &t
+			courierSince = t
+		case grabexpress.OrderStatusInDelivery:
+			timeline.Pickup = &t
+		case grabexpress.OrderStatusCompleted:
+			timeline.DropOff = &t
+			timeline.Completed = &t
+		}
+	}
+	if timeline.Create == nil {
+		created := d.createdAt
+		timeline.Create = &created
+	}
+
+	result := grabexpress.Delivery{
+		DeliveryID:      d.id,
+		MerchantOrderID: d.req.MerchantOrderID,
+		Quote: grabexpress.Quote{
+			QuoteBase:   d.quote,
+			Packages:    d.req.Packages,
+			Origin:      d.req.Origin,
+			Destination: d.req.Destination,
+		},
+		Status:         status,
+		TrackingURL:    d.trackingURL,
+		Timeline:       timeline,
+		Schedule:       d.req.Schedule,
+		CashOnDelivery: d.req.CashOnDelivery,
+		InvoiceNumber:  "INV-" + d.id,
+		PickupPin:      d.pickupPin,
+		Sender:         d.req.Sender,
+		Recipient:      d.req.Recipient,
+	}
+	if d.req.PaymentMethod != nil {
+		result.PaymentMethod = *d.req.PaymentMethod
+	} else {
+		result.PaymentMethod = grabexpress.PaymentMethodCashless
+	}
+	if !courierSince.IsZero() {
+		result.Courier = d.courier(status, timeline, at)
+	}
+	if d.endStatus != "" {
+		result.Status = d.endStatus
+		ended := d.endedAt
+		switch d.endStatus {
+		case grabexpress.OrderStatusCanceled:
+			timeline.Cancel = &ended
+		case grabexpress.OrderStatusReturned:
+			timeline.Return = &ended
+		case grabexpress.OrderStatusFailed:
+			timeline.Fail = &ended
+			result.AdvanceInfo = &grabexpress.AdvanceInfo{FailedReason: d.reason}
+		case grabexpress.OrderStatusCompleted:
+			timeline.DropOff = &ended
+			timeline.Completed = &ended
+		}
+	}
+	return result
+}
+
+// courier returns the simulated courier, moving from near the origin to the origin while picking up,
+// then from the origin to the destination while delivering.
+func (d *delivery) courier(status grabexpress.OrderStatus, timeline *grabexpress.Timeline, at time.Time) *grabexpress.Courier {
+	origin := d.req.Origin.Coordinates
+	destination := d.req.Destination.Coordinates
+	start := grabexpress.Coordinates{Latitude: origin.Latitude + 0.01, Longitude: origin.Longitude + 0.01}
+
+	position := origin
+	switch {
+	case status == grabexpress.OrderStatusPickingUp && timeline.Allocate != nil:
+		position = interpolate(start, origin, progress(*timeline.Allocate, timeline.Allocate.Add(pickupDelay), at))
+	case status == grabexpress.OrderStatusInDelivery && timeline.Pickup != nil:
+		travel := time.Duration(distanceKm(origin, destination)/d.speed()) * time.Minute
+		position = interpolate(origin, destination, progress(*timeline.Pickup, timeline.Pickup.Add(travel), at))
+	case status == grabexpress.OrderStatusCompleted:
+		position = destination
+	}
+	return &grabexpress.Courier{
+		Name:        "Simulated Courier",
+		Phone:       "91234567",
+		PictureURL:  "https://example.com/courier.png",
+		Rating:      4.9,
+		Coordinates: position,
+		Vehicle: grabexpress.Vehicle{
+			LicensePlate:        "SIM 1234",
+			Model:               "Honda Wave",
+			PhysicalVehicleType: "MOTORCYCLE",
+		},
+	}
+}
+
+func (d *delivery) speed() float64 {
+	for _, s := range services {
+		if s.service.Type == d.quote.Service.Type {
+			return s.speed
+		}
+	}
+	return services[0].speed
+}
+
+// quote prices every simulated service, or only serviceType when given.
+func quote(serviceType grabexpress.ServiceType, packages []grabexpress.Package, origin, destination grabexpress.Waypoint, now time.Time) []grabexpress.QuoteBase {
+	km := distanceKm(origin.Coordinates, destination.Coordinates)
+	currency := currencies[grabexpress.CountryCodeSingapore]
+	if origin.CityCode != nil {
+		if c, ok := currencies[grabexpress.CityCode(*origin.CityCode).GetCountry().Code]; ok {
+			currency = c
+		}
+	}
+	quantity := int64(0)
+	for _, p := range packages {
+		quantity += p.Quantity
+	}
+	var quotes []grabexpress.QuoteBase
+	for _, s := range services {
+		if serviceType != "" && s.service.Type != serviceType {
+			continue
+		}
+		amount := (s.base + s.perKm*km + 0.5*float64(quantity)) * currency.scale
+		unit := math.Pow10(int(currency.currency.Exponent))
+		pickup := now.Add(pickupDelay)
+		dropoff := pickup.Add(time.Duration(km/s.speed) * time.Minute)
+		quotes = append(quotes, grabexpress.QuoteBase{
+			Service:  s.service,
+			Currency: currency.currency,
+			Amount:   math.Round(amount*unit) / unit,
+			EstimatedTimeline: &grabexpress.Timeline{
+				Pickup:  &pickup,
+				DropOff: &dropoff,
+			},
+			Distance: int64(km * 1000),
+		})
+	}
+	return quotes
+}
+
+func progress(from, to, at time.Time) float64 {
+	if !to.After(from) || !at.Before(to) {
+		return 1
+	}
+	if at.Before(from) {
+		return 0
+	}
+	return float64(at.Sub(from)) / float64(to.Sub(from))
+}
+
+func interpolate(a, b grabexpress.Coordinates, f float64) grabexpress.Coordinates {
+	return grabexpress.Coordinates{
+		Latitude:  a.Latitude + (b.Latitude-a.Latitude)*f,
+		Longitude: a.Longitude + (b.Longitude-a.Longitude)*f,
+	}
+}
+
+// distanceKm returns the great-circle distance between a and b.
+func distanceKm(a, b grabexpress.Coordinates) float64 {
+	const earthRadiusKm = 6371
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(b.Latitude - a.Latitude)
+	dLng := rad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(a.Latitude))*math.Cos(rad(b.Latitude))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,442 @@
+// Package grabexpresstest provides a local simulator of the GrabExpress API for integration tests.
+//
+// The simulator implements the OAuth token endpoint and the /v1/deliveries routes, moves deliveries
+// through their lifecycle as its virtual clock advances, and can be scripted to fail.
+package grabexpresstest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+// Default credentials accepted by the simulator.
+const (
+	DefaultAPIKey = "grabexpresstest-api-key"
+	DefaultSecret = "grabexpresstest-secret"
+)
+
+const (
+	tokenPath      = "/grabid/v1/oauth2/token"
+	deliveriesPath = "/v1/deliveries"
+	tokenLifetime  = time.Hour
+)
+
+// Server is a running GrabExpress API simulator.
+type Server struct {
+	*httptest.Server
+
+	apiKey    string
+	secret    string
+	lifecycle []Step
+
+	mu         sync.Mutex
+	now        time.Time
+	latency    time.Duration
+	tokens     map[string]bool
+	deliveries map[string]*delivery
+	orders     map[string]string
+	failures   []*Failure
+	requests   []Request
+	sequence   int
+}
+
+// Step is a stage of the simulated delivery lifecycle: a delivery reaches Status After its creation.
+type Step struct {
+	Status grabexpress.OrderStatus
+	After  time.Duration
+}
+
+// DefaultLifecycle is the lifecycle of a successful delivery.
+var DefaultLifecycle = []Step{
+	{Status: grabexpress.OrderStatusQueueing, After: 0},
+	{Status: grabexpress.OrderStatusAllocating, After: time.Minute},
+	{Status: grabexpress.OrderStatusPickingUp, After: 2 * time.Minute},
+	{Status: grabexpress.OrderStatusInDelivery, After: 12 * time.Minute},
+	{Status: grabexpress.OrderStatusCompleted, After: 32 * time.Minute},
+}
+
+// Request is a request received by the simulator.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	At     time.Time
+}
+
+// Failure scripts the simulator to answer matching requests with an error, or slowly.
+type Failure struct {
+	// Method and Path restrict the requests the failure applies to. Path matches by prefix.
+	// Empty values match every request.
+	Method string
+	Path   string
+	// Status is the HTTP status to answer with. Zero only applies Delay, then serves the request.
+	Status int
+	// Body is the response body. Defaults to a JSON error describing Status.
+	Body string
+	// RetryAfter sets the Retry-After header when positive.
+	RetryAfter time.Duration
+	// Delay is how long to wait, in real time, before answering.
+	Delay time.Duration
+	// Times is how many requests the failure applies to. Zero means every matching request.
+	Times int
+}
+
+// Option is the type of constructor options for NewServer(...).
+type Option func(*Server)
+
+// WithCredentials configures the credentials the simulator accepts
+func WithCredentials(apiKey, secret string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+		s.secret = secret
+	}
+}
+
+// WithLifecycle configures the lifecycle simulated deliveries go through
+func WithLifecycle(steps []Step) Option {
+	return func(s *Server) {
+		s.lifecycle = steps
+	}
+}
+
+// WithStartTime configures the initial time of the virtual clock
+func WithStartTime(t time.Time) Option {
+	return func(s *Server) {
+		s.now = t
+	}
+}
+
+// NewServer starts a simulator. It should be closed when done.
+func NewServer(options ...Option) *Server {
+	s := &Server{
+		apiKey:     DefaultAPIKey,
+		secret:     DefaultSecret,
+		lifecycle:  DefaultLifecycle,
+		now:        time.Now().UTC().Truncate(time.Second),
+		tokens:     map[string]bool{},
+		deliveries: map[string]*delivery{},
+		orders:     map[string]string{},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(tokenPath, s.handleToken)
+	mux.HandleFunc(deliveriesPath, s.authenticated(s.handleDeliveries))
+	mux.HandleFunc(deliveriesPath+"/", s.authenticated(s.handleDelivery))
+	s.Server = httptest.NewServer(s.intercept(mux))
+	return s
+}
+
+// BaseURL returns the base URL of the simulated API.
+func (s *Server) BaseURL() string {
+	return s.URL
+}
+
+// TokenURL returns the URL of the simulated token endpoint.
+func (s *Server) TokenURL() string {
+	return s.URL + tokenPath
+}
+
+// Environment returns the environment of the simulator, for use with grabexpress.WithEnvironment.
+func (s *Server) Environment() grabexpress.Environment {
+	return grabexpress.LocalEnvironment(s.BaseURL())
+}
+
+// Client constructs a grabexpress.Client talking to the simulator. Options are applied after the defaults.
+func (s *Server) Client(options ...grabexpress.ClientOption) (*grabexpress.Client, error) {
+	defaults := []grabexpress.ClientOption{
+		grabexpress.WithAPIKey(s.apiKey),
+		grabexpress.WithSecret(s.secret),
+		grabexpress.WithEnvironment(s.Environment()),
+	}
+	return grabexpress.NewClient(append(defaults, options...)...)
+}
+
+// Now returns the time of the virtual clock.
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the virtual clock forward, progressing deliveries through their lifecycle.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// SetLatency delays every response by d, in real time.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Fail scripts a failure. Failures are matched in the order they were added.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// ClearFailures removes all scripted failures.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// RevokeTokens invalidates every access token issued so far, so that the next API calls get a 401.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]bool{}
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Delivery returns the current state of a simulated delivery.
+func (s *Server) Delivery(deliveryID string) (grabexpress.Delivery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[deliveryID]
+	if !ok {
+		return grabexpress.Delivery{}, false
+	}
+	return d.snapshot(s.now, s.lifecycle), true
+}
+
+// SetStatus forces a simulated delivery into a terminal status, e.g. FAILED or RETURNED.
+func (s *Server) SetStatus(deliveryID string, status grabexpress.OrderStatus, reason string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[deliveryID]
+	if !ok {
+		return false
+	}
+	d.end(status, s.now, reason)
+	return true
+}
+
+// intercept records requests and applies latency and scripted failures.
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), At: s.now})
+		latency := s.latency
+		failure := s.matchFailure(r)
+		s.mu.Unlock()
+
+		if failure != nil {
+			latency += failure.Delay
+		}
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if failure == nil || failure.Status == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if failure.RetryAfter > 0 {
+			w.Header().Set("Retry-After", fmt.Sprint(int(failure.RetryAfter.Seconds())))
+		}
+		if failure.Body != "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(failure.Status)
+			fmt.Fprint(w, failure.Body)
+			return
+		}
+		writeError(w, failure.Status, "simulated_failure", http.StatusText(failure.Status))
+	})
+}
+
+// matchFailure returns the first failure applying to r, consuming it. s.mu must be held.
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if (f.Method != "" && f.Method != r.Method) || !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "token requests must be POST")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if clientID != s.apiKey || secret != s.secret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	token := randomHex(16)
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenLifetime.Seconds()),
+	})
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		ok := s.tokens[token]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "invalid or expired access token")
+			return
+		}
+		w.Header().Set("X-Grabkit-Grab-Requestid", randomHex(8))
+		next(w, r)
+	}
+}
+
+func (s *Server) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "deliveries must be created with POST")
+		return
+	}
+	var req grabexpress.CreateDeliveryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if req.MerchantOrderID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "merchantOrderID is required")
+		return
+	}
+	if req.ServiceType == "" {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "serviceType is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orders[req.MerchantOrderID]; ok {
+		writeError(w, http.StatusConflict, "duplicate_merchant_order_id", "merchantOrderID already used")
+		return
+	}
+	quotes := quote(req.ServiceType, req.Packages, req.Origin, req.Destination, s.now)
+	if len(quotes) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "unsupported serviceType")
+		return
+	}
+	s.sequence++
+	d := newDelivery(fmt.Sprintf("SIM-%06d", s.sequence), &req, quotes[0], s.now, s.URL)
+	s.deliveries[d.id] = d
+	s.orders[req.MerchantOrderID] = d.id
+	writeJSON(w, http.StatusOK, d.snapshot(s.now, s.lifecycle))
+}
+
+func (s *Server) handleDelivery(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, deliveriesPath+"/")
+	if id == "quotes" {
+		s.handleQuotes(w, r)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "delivery not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, d.snapshot(s.now, s.lifecycle))
+	case http.MethodDelete:
+		status := d.snapshot(s.now, s.lifecycle).Status
+		if !status.CanCancel() {
+			writeError(w, http.StatusConflict, "cannot_cancel", fmt.Sprintf("delivery is %s and can no longer be canceled", status))
+			return
+		}
+		d.end(grabexpress.OrderStatusCanceled, s.now, "")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "unsupported method")
+	}
+}
+
+func (s *Server) handleQuotes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "quotes must be requested with POST")
+		return
+	}
+	var req grabexpress.CreateQuotesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	var serviceType grabexpress.ServiceType
+	if req.ServiceType != nil {
+		serviceType = *req.ServiceType
+	}
+	s.mu.Lock()
+	now := s.now
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, grabexpress.CreateQuotesResponse{
+		Quotes:      quote(serviceType, req.Packages, req.Origin, req.Destination, now),
+		Packages:    req.Packages,
+		Origin:      req.Origin,
+		Destination: req.Destination,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]string{"code": code, "message": message})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
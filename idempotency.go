@@ -0,0 +1,354 @@
+package grabexpress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// IdempotencyState ...
+type IdempotencyState string
+
+// IdempotencyState enum
+const (
+	// IdempotencyStateInFlight - a delivery creation was started but its outcome is not known yet
+	IdempotencyStateInFlight IdempotencyState = "IN_FLIGHT"
+	// IdempotencyStateCompleted - the delivery was created
+	IdempotencyStateCompleted IdempotencyState = "COMPLETED"
+)
+
+// IdempotencyRecord is what an IdempotencyStore remembers about a delivery creation.
+type IdempotencyRecord struct {
+	Key         string           `json:"key"`
+	State       IdempotencyState `json:"state"`
+	DeliveryID  string           `json:"deliveryID,omitempty"`
+	StartedAt   time.Time        `json:"startedAt"`
+	CompletedAt *time.Time       `json:"completedAt,omitempty"`
+}
+
+// IdempotencyStore remembers in-flight and completed delivery creations by idempotency key,
+// so that CreateDelivery never books the same order twice.
+type IdempotencyStore interface {
+	// Reserve atomically records key as in flight. When a record already exists for key,
+	// it is returned and reserved is false.
+	Reserve(ctx context.Context, key string) (record *IdempotencyRecord, reserved bool, err error)
+	// Complete marks key as completed with the created delivery.
+	Complete(ctx context.Context, key, deliveryID string) error
+	// Release forgets key, once it is certain that no delivery was created for it.
+	Release(ctx context.Context, key string) error
+	// Takeover atomically replaces the in-flight record of key started at startedAt by a new in-flight record.
+	// When the record changed meanwhile, e.g. because another caller took it over first, the current record is
+	// returned and reserved is false.
+	Takeover(ctx context.Context, key string, startedAt time.Time) (record *IdempotencyRecord, reserved bool, err error)
+}
+
+// DeliveryLookupFunc finds the delivery created for a merchant order ID.
+// It returns a nil Delivery when no such delivery exists.
+type DeliveryLookupFunc func(ctx context.Context, merchantOrderID string) (*Delivery, error)
+
+// WithIdempotencyStore configures a GrabExpress API client to deduplicate CreateDelivery calls through store
+func WithIdempotencyStore(store IdempotencyStore) ClientOption {
+	return func(c *Client) error {
+		c.idempotencyStore = store
+		return nil
+	}
+}
+
+// WithInFlightTimeout configures a GrabExpress API client to take over a delivery creation left in flight
+// for longer than d, e.g. by a process that crashed, when the delivery lookup cannot find it either.
+// The creation is then attempted again. Without a delivery lookup, a creation whose outcome was unknown may
+// thus be booked twice. Zero, the default, keeps such creations in flight until resolved manually,
+// see ErrDeliveryInFlight.
+func WithInFlightTimeout(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.inFlightTimeout = d
+		return nil
+	}
+}
+
+// WithDeliveryLookup configures a GrabExpress API client to recover deliveries whose creation outcome is unknown
+func WithDeliveryLookup(lookup DeliveryLookupFunc) ClientOption {
+	return func(c *Client) error {
+		c.deliveryLookup = lookup
+		return nil
+	}
+}
+
+// idempotencyKey returns the key identifying the delivery creation, defaulting to the merchant order ID.
+func (r *CreateDeliveryRequest) idempotencyKey() string {
+	if r.IdempotencyKey != "" {
+		return r.IdempotencyKey
+	}
+	return r.MerchantOrderID
+}
+
+// createDeliveryOnce creates a delivery unless one was already created, or is being created, for the same key.
+// A creation left in flight before resumedAt, when not zero, is taken over like a stale one, but only once the
+// delivery lookup confirmed that it was not booked.
+func (c *Client) createDeliveryOnce(ctx context.Context, req *CreateDeliveryRequest, resumedAt time.Time) (*CreateDeliveryResponse, error) {
+	key := req.idempotencyKey()
+	if c.idempotencyStore == nil || key == "" {
+		return c.createDelivery(ctx, req)
+	}
+	record, reserved, err := c.idempotencyStore.Reserve(ctx, key)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if !reserved {
+		resp, err := c.recoverDelivery(ctx, req, record)
+		if err != ErrDeliveryInFlight || !c.stale(record, resumedAt) {
+			return resp, err
+		}
+		if record, reserved, err = c.idempotencyStore.Takeover(ctx, key, record.StartedAt); err != nil {
+			return nil, wrapError(err)
+		}
+		if !reserved {
+			// Another caller took it over first.
+			return c.recoverDelivery(ctx, req, record)
+		}
+	}
+	resp, err := c.createDelivery(ctx, req)
+	if err != nil {
+		if !isAmbiguous(err) {
+			_ = c.idempotencyStore.Release(ctx, key)
+		}
+		return nil, err
+	}
+	// Should completing fail, the key stays in flight, which still prevents a duplicate booking.
+	_ = c.idempotencyStore.Complete(ctx, key, resp.DeliveryID)
+	return resp, nil
+}
+
+// recoverDelivery returns the delivery already created for record instead of creating a duplicate.
+func (c *Client) recoverDelivery(ctx context.Context, req *CreateDeliveryRequest, record *IdempotencyRecord) (*CreateDeliveryResponse, error) {
+	deliveryID := record.DeliveryID
+	if record.State != IdempotencyStateCompleted || deliveryID == "" {
+		if c.deliveryLookup == nil {
+			return nil, ErrDeliveryInFlight
+		}
+		delivery, err := c.deliveryLookup(ctx, req.MerchantOrderID)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		if delivery == nil {
+			return nil, ErrDeliveryInFlight
+		}
+		deliveryID = delivery.DeliveryID
+		_ = c.idempotencyStore.Complete(ctx, record.Key, deliveryID)
+	}
+	resp, err := c.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return &CreateDeliveryResponse{
+		BaseDTO:  resp.BaseDTO,
+		Delivery: resp.Delivery,
+	}, nil
+}
+
+// stale reports whether record was left in flight for longer than the in-flight timeout, or before resumedAt.
+// It is only called once recoverDelivery found no delivery for record: a creation left in flight before resumedAt
+// is then stale when a delivery lookup actually searched for it.
+func (c *Client) stale(record *IdempotencyRecord, resumedAt time.Time) bool {
+	if record.State != IdempotencyStateInFlight {
+		return false
+	}
+	if c.inFlightTimeout > 0 && time.Since(record.StartedAt) > c.inFlightTimeout {
+		return true
+	}
+	return c.deliveryLookup != nil && record.StartedAt.Before(resumedAt)
+}
+
+// isAmbiguous reports whether a delivery may have been created despite err.
+// A 409 Conflict means that a delivery already exists for the merchant order ID.
+func isAmbiguous(err error) bool {
+	apiErr, ok := err.(*Error)
+	if !ok {
+		return true
+	}
+	if apiErr.auth {
+		return false
+	}
+	succeeded := apiErr.Status >= 200 && apiErr.Status < 300
+	return succeeded || apiErr.Status == http.StatusConflict || apiErr.transport || errors.Is(apiErr, ErrServer) ||
+		errors.Is(apiErr, context.Canceled) || errors.Is(apiErr, context.DeadlineExceeded)
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore kept in memory, suitable for a single process.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore ...
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: map[string]IdempotencyRecord{}}
+}
+
+// Reserve ...
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key string) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok {
+		return &record, false, nil
+	}
+	record := IdempotencyRecord{Key: key, State: IdempotencyStateInFlight, StartedAt: time.Now()}
+	s.records[key] = record
+	return &record, true, nil
+}
+
+// Complete ...
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key, deliveryID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = completeRecord(s.records[key], key, deliveryID)
+	return nil
+}
+
+// Release ...
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// Takeover ...
+func (s *MemoryIdempotencyStore) Takeover(ctx context.Context, key string, startedAt time.Time) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if ok && !takesOver(record, startedAt) {
+		return &record, false, nil
+	}
+	record = IdempotencyRecord{Key: key, State: IdempotencyStateInFlight, StartedAt: time.Now()}
+	s.records[key] = record
+	return &record, true, nil
+}
+
+// FileIdempotencyStore is an IdempotencyStore persisted as a JSON file, so that it survives restarts.
+// It is safe for concurrent use within a process, but not across processes sharing the same file.
+type FileIdempotencyStore struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]IdempotencyRecord
+}
+
+// NewFileIdempotencyStore opens the store at path, creating it on first write.
+func NewFileIdempotencyStore(path string) (*FileIdempotencyStore, error) {
+	s := &FileIdempotencyStore{path: path, records: map[string]IdempotencyRecord{}}
+	bb, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bb) > 0 {
+		if err := json.Unmarshal(bb, &s.records); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Reserve ...
+func (s *FileIdempotencyStore) Reserve(ctx context.Context, key string) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok {
+		return &record, false, nil
+	}
+	record := IdempotencyRecord{Key: key, State: IdempotencyStateInFlight, StartedAt: time.Now()}
+	s.records[key] = record
+	if err := s.flush(); err != nil {
+		delete(s.records, key)
+		return nil, false, err
+	}
+	return &record, true, nil
+}
+
+// Complete ...
+func (s *FileIdempotencyStore) Complete(ctx context.Context, key, deliveryID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = completeRecord(s.records[key], key, deliveryID)
+	return s.flush()
+}
+
+// Release ...
+func (s *FileIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return s.flush()
+}
+
+// Takeover ...
+func (s *FileIdempotencyStore) Takeover(ctx context.Context, key string, startedAt time.Time) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, ok := s.records[key]
+	if ok && !takesOver(previous, startedAt) {
+		return &previous, false, nil
+	}
+	record := IdempotencyRecord{Key: key, State: IdempotencyStateInFlight, StartedAt: time.Now()}
+	s.records[key] = record
+	if err := s.flush(); err != nil {
+		if ok {
+			s.records[key] = previous
+		} else {
+			delete(s.records, key)
+		}
+		return nil, false, err
+	}
+	return &record, true, nil
+}
+
+// flush atomically replaces the file with the current records.
+func (s *FileIdempotencyStore) flush() error {
+	bb, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(bb); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// takesOver reports whether record is still the in-flight record started at startedAt.
+func takesOver(record IdempotencyRecord, startedAt time.Time) bool {
+	return record.State == IdempotencyStateInFlight && record.StartedAt.Equal(startedAt)
+}
+
+func completeRecord(record IdempotencyRecord, key, deliveryID string) IdempotencyRecord {
+	now := time.Now()
+	if record.StartedAt.IsZero() {
+		record.StartedAt = now
+	}
+	record.Key = key
+	record.State = IdempotencyStateCompleted
+	record.DeliveryID = deliveryID
+	record.CompletedAt = &now
+	return record
+}
@@ -0,0 +1,245 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+// lostResponses answers the first n delivery creations with 503 Service Unavailable once the API handled them,
+// as a gateway losing the response would.
+type lostResponses struct {
+	n int32
+}
+
+func (t *lostResponses) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || req.Method != http.MethodPost || req.URL.Path != "/v1/deliveries" || atomic.AddInt32(&t.n, -1) < 0 {
+		return resp, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return &http.Response{
+		Status:     "503 Service Unavailable",
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"code":"unavailable","message":"upstream timeout"}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestCreateDeliveryDoesNotRetryAmbiguousFailures(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	store := grabexpress.NewMemoryIdempotencyStore()
+	client, err := srv.Client(
+		grabexpress.WithHTTPClient(&http.Client{Transport: &lostResponses{n: 1}}),
+		grabexpress.WithRetryPolicy(fastRetries),
+		grabexpress.WithIdempotencyStore(store),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	req := newCreateDeliveryRequest("ORDER-1")
+
+	if _, err := client.CreateDelivery(ctx, req); !errors.Is(err, grabexpress.ErrServer) {
+		t.Fatalf("CreateDelivery() error = %v, want ErrServer", err)
+	}
+	if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != 1 {
+		t.Fatalf("sent %d creations, want 1", n)
+	}
+
+	// The delivery may exist: the key stays in flight, and the creation is not sent again.
+	if _, err := client.CreateDelivery(ctx, req); !errors.Is(err, grabexpress.ErrDeliveryInFlight) {
+		t.Fatalf("CreateDelivery() again error = %v, want ErrDeliveryInFlight", err)
+	}
+	if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != 1 {
+		t.Fatalf("sent %d creations, want 1", n)
+	}
+}
+
+func TestCreateDeliveryRecoversThroughLookup(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	var created string
+	lookup := func(ctx context.Context, merchantOrderID string) (*grabexpress.Delivery, error) {
+		if created == "" {
+			return nil, nil
+		}
+		d, _ := srv.Delivery(created)
+		return &d, nil
+	}
+	client, err := srv.Client(
+		grabexpress.WithHTTPClient(&http.Client{Transport: &lostResponses{n: 1}}),
+		grabexpress.WithIdempotencyStore(grabexpress.NewMemoryIdempotencyStore()),
+		grabexpress.WithDeliveryLookup(lookup),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	req := newCreateDeliveryRequest("ORDER-1")
+
+	if _, err := client.CreateDelivery(ctx, req); err == nil {
+		t.Fatal("CreateDelivery() succeeded despite the lost response")
+	}
+	created = "SIM-000001"
+	resp, err := client.CreateDelivery(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateDelivery() again error = %v", err)
+	}
+	if resp.DeliveryID != created {
+		t.Errorf("DeliveryID = %q, want %q", resp.DeliveryID, created)
+	}
+	if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != 1 {
+		t.Errorf("sent %d creations, want 1", n)
+	}
+}
+
+func TestCreateDeliveryKeepsKeyOnConflict(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	other, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := srv.Client(grabexpress.WithIdempotencyStore(grabexpress.NewMemoryIdempotencyStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	var apiErr *grabexpress.Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusConflict {
+		t.Fatalf("CreateDelivery() error = %v, want 409 Conflict", err)
+	}
+	if _, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1")); !errors.Is(err, grabexpress.ErrDeliveryInFlight) {
+		t.Fatalf("CreateDelivery() again error = %v, want ErrDeliveryInFlight", err)
+	}
+	if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != 2 {
+		t.Errorf("sent %d creations, want 2", n)
+	}
+}
+
+func TestCreateDeliveryDeduplicatesCompletedKeys(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client(grabexpress.WithIdempotencyStore(grabexpress.NewMemoryIdempotencyStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	first, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatalf("CreateDelivery() again error = %v", err)
+	}
+	if second.DeliveryID != first.DeliveryID {
+		t.Errorf("DeliveryID = %q, want %q", second.DeliveryID, first.DeliveryID)
+	}
+	if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != 1 {
+		t.Errorf("sent %d creations, want 1", n)
+	}
+}
+
+func TestCreateDeliveryRetriesTooManyRequests(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	srv.Fail(grabexpresstest.Failure{Method: http.MethodPost, Path: "/v1/deliveries", Status: http.StatusTooManyRequests, Times: 1})
+	client, err := srv.Client(grabexpress.WithRetryPolicy(fastRetries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateDelivery(context.Background(), newCreateDeliveryRequest("ORDER-1")); err != nil {
+		t.Fatalf("CreateDelivery() error = %v", err)
+	}
+	if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != 2 {
+		t.Errorf("sent %d creations, want 2", n)
+	}
+}
+
+func TestIdempotencyStoreTakeover(t *testing.T) {
+	file, err := grabexpress.NewFileIdempotencyStore(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]grabexpress.IdempotencyStore{
+		"memory": grabexpress.NewMemoryIdempotencyStore(),
+		"file":   file,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			stale, _, err := store.Reserve(ctx, "ORDER-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			var takenOver int32
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, reserved, err := store.Takeover(ctx, "ORDER-1", stale.StartedAt); err != nil {
+						t.Error(err)
+					} else if reserved {
+						atomic.AddInt32(&takenOver, 1)
+					}
+				}()
+			}
+			wg.Wait()
+			if takenOver != 1 {
+				t.Errorf("%d callers took the record over, want 1", takenOver)
+			}
+
+			if err := store.Complete(ctx, "ORDER-1", "SIM-000001"); err != nil {
+				t.Fatal(err)
+			}
+			record, reserved, err := store.Takeover(ctx, "ORDER-1", stale.StartedAt)
+			if err != nil || reserved || record.State != grabexpress.IdempotencyStateCompleted {
+				t.Errorf("Takeover() of a completed record = %+v, %t, %v", record, reserved, err)
+			}
+		})
+	}
+}
+
+func TestCreateDeliveryTakesOverStaleKeys(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	store := grabexpress.NewMemoryIdempotencyStore()
+	ctx := context.Background()
+	if _, _, err := store.Reserve(ctx, "ORDER-1"); err != nil {
+		t.Fatal(err)
+	}
+	client, err := srv.Client(grabexpress.WithIdempotencyStore(store), grabexpress.WithInFlightTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1")); err != nil {
+		t.Fatalf("CreateDelivery() of a stale key error = %v", err)
+	}
+	if n := countRequests(srv, http.MethodPost, "/v1/deliveries"); n != 1 {
+		t.Errorf("sent %d creations, want 1", n)
+	}
+}
@@ -0,0 +1,93 @@
+package grabexpress
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LogOption is the type of options for WithLogger.
+type LogOption func(*callLogger)
+
+// WithLogLevel sets the level of successful calls. Defaults to slog.LevelInfo; failed calls are logged as errors.
+func WithLogLevel(level slog.Level) LogOption {
+	return func(l *callLogger) {
+		l.level = level
+	}
+}
+
+// WithLogBodies also logs the request and response bodies, masked by redactor, or by NewRedactor() when nil.
+func WithLogBodies(redactor *Redactor) LogOption {
+	return func(l *callLogger) {
+		if redactor == nil {
+			redactor = NewRedactor()
+		}
+		l.redactor = redactor
+	}
+}
+
+// WithLogger configures a GrabExpress API client to log every API call to logger, with its method, path, status,
+// duration and request ID.
+func WithLogger(logger *slog.Logger, options ...LogOption) ClientOption {
+	return func(c *Client) error {
+		l := &callLogger{logger: logger, level: slog.LevelInfo}
+		for _, option := range options {
+			option(l)
+		}
+		c.logger = l
+		return nil
+	}
+}
+
+// callLogger logs API calls.
+type callLogger struct {
+	logger   *slog.Logger
+	level    slog.Level
+	redactor *Redactor
+}
+
+func (l *callLogger) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(ctx context.Context, call *Call) error {
+		err := next(ctx, call)
+
+		level := l.level
+		if err != nil {
+			level = slog.LevelError
+		}
+		if !l.logger.Enabled(ctx, level) {
+			return err
+		}
+		attrs := []slog.Attr{
+			slog.String("operation", call.Operation),
+			slog.String("method", call.Method),
+			slog.String("path", call.Path),
+			slog.Int("status", call.Status),
+			slog.Duration("duration", time.Since(call.Start)),
+			slog.Int("attempts", call.Attempts),
+		}
+		if id := call.ResponseHeader.Get(requestIDHeader); id != "" {
+			attrs = append(attrs, slog.String("requestID", id))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()), slog.String("category", ErrorCategory(err)))
+		}
+		if l.redactor != nil {
+			if call.Request != nil {
+				attrs = append(attrs, l.body("request", call.Request))
+			}
+			if err == nil && call.Response != nil {
+				attrs = append(attrs, l.body("response", call.Response))
+			}
+		}
+		l.logger.LogAttrs(ctx, level, "grabexpress API call", attrs...)
+		return err
+	}
+}
+
+func (l *callLogger) body(key string, v interface{}) slog.Attr {
+	bb, err := l.redactor.Redact(v)
+	if err != nil {
+		return slog.String(key, "unloggable body: "+err.Error())
+	}
+	return slog.String(key, string(bb))
+}
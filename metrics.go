@@ -0,0 +1,238 @@
+package grabexpress
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request latency histogram.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Error category labels, see ErrorCategory.
+const (
+	CategoryValidation   = "validation"
+	CategoryNotFound     = "not_found"
+	CategoryUnauthorized = "unauthorized"
+	CategoryRateLimited  = "rate_limited"
+	CategoryCannotCancel = "cannot_cancel"
+	CategoryServer       = "server"
+	CategoryTransport    = "transport"
+	CategoryAuth         = "auth"
+	CategoryCanceled     = "canceled"
+	CategoryOther        = "other"
+)
+
+// ErrorCategory classifies an error returned by the client into a low-cardinality label for metrics and logs.
+func ErrorCategory(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return CategoryCanceled
+	case errors.Is(err, ErrAuthenticationError):
+		return CategoryAuth
+	case errors.Is(err, ErrValidation):
+		return CategoryValidation
+	case errors.Is(err, ErrNotFound):
+		return CategoryNotFound
+	case errors.Is(err, ErrUnauthorized):
+		return CategoryUnauthorized
+	case errors.Is(err, ErrRateLimited):
+		return CategoryRateLimited
+	case errors.Is(err, ErrCannotCancel):
+		return CategoryCannotCancel
+	case errors.Is(err, ErrServer):
+		return CategoryServer
+	case errors.Is(err, ErrTransport):
+		return CategoryTransport
+	}
+	return CategoryOther
+}
+
+// Metrics records the health of the clients it is attached to, see WithMetrics.
+// It may be shared by several clients, and exported through expvar or a Prometheus collector.
+type Metrics struct {
+	buckets []float64
+
+	mu                 sync.Mutex
+	endpoints          map[Endpoint]map[string]*endpointMetrics
+	tokenRefreshes     uint64
+	tokenRefreshErrors uint64
+}
+
+type endpointMetrics struct {
+	requests        uint64
+	retries         uint64
+	inFlight        int64
+	errors          map[string]uint64
+	rateLimitWaits  uint64
+	rateLimitWaited time.Duration
+	latencyCounts   []uint64
+	latencySum      float64
+}
+
+// NewMetrics constructs a Metrics recording latencies into the given histogram buckets,
+// or DefaultLatencyBuckets when none are given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{buckets: buckets, endpoints: map[Endpoint]map[string]*endpointMetrics{}}
+}
+
+// WithMetrics configures a GrabExpress API client to record its health into m
+func WithMetrics(m *Metrics) ClientOption {
+	return func(c *Client) error {
+		c.metrics = m
+		return nil
+	}
+}
+
+// endpoint returns the metrics of the operation calls to e. m.mu must be held.
+func (m *Metrics) endpoint(e Endpoint, operation string) *endpointMetrics {
+	operations, ok := m.endpoints[e]
+	if !ok {
+		operations = map[string]*endpointMetrics{}
+		m.endpoints[e] = operations
+	}
+	em, ok := operations[operation]
+	if !ok {
+		em = &endpointMetrics{errors: map[string]uint64{}, latencyCounts: make([]uint64, len(m.buckets)+1)}
+		operations[operation] = em
+	}
+	return em
+}
+
+// middleware records every API round-trip.
+func (m *Metrics) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(ctx context.Context, call *Call) error {
+		m.mu.Lock()
+		m.endpoint(call.Endpoint, call.Operation).inFlight++
+		m.mu.Unlock()
+
+		err := next(ctx, call)
+		elapsed := time.Since(call.Start).Seconds()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		em := m.endpoint(call.Endpoint, call.Operation)
+		em.inFlight--
+		em.requests++
+		if call.Attempts > 1 {
+			em.retries += uint64(call.Attempts - 1)
+		}
+		if err != nil {
+			em.errors[ErrorCategory(err)]++
+		}
+		em.latencyCounts[sort.SearchFloat64s(m.buckets, elapsed)]++
+		em.latencySum += elapsed
+		return err
+	}
+}
+
+func (m *Metrics) observeRateLimitWait(e Endpoint, operation string, waited time.Duration) {
+	if m == nil || waited <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	em := m.endpoint(e, operation)
+	em.rateLimitWaits++
+	em.rateLimitWaited += waited
+}
+
+func (m *Metrics) observeTokenRefresh(err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokenRefreshes++
+	if err != nil {
+		m.tokenRefreshErrors++
+	}
+}
+
+// MetricsSnapshot is a point-in-time copy of Metrics.
+type MetricsSnapshot struct {
+	// Endpoints holds the metrics of each endpoint by operation, i.e. Call.Operation.
+	Endpoints          map[Endpoint]map[string]EndpointSnapshot `json:"endpoints"`
+	TokenRefreshes     uint64                                   `json:"tokenRefreshes"`
+	TokenRefreshErrors uint64                                   `json:"tokenRefreshErrors"`
+}
+
+// EndpointSnapshot holds the metrics of the calls of an operation to an endpoint.
+type EndpointSnapshot struct {
+	// Requests is the number of completed API calls, Retries the number of extra attempts they took.
+	Requests uint64 `json:"requests"`
+	Retries  uint64 `json:"retries"`
+	InFlight int64  `json:"inFlight"`
+	// Errors counts failed calls by ErrorCategory.
+	Errors map[string]uint64 `json:"errors"`
+	// RateLimitWaits counts the calls held back by the client-side rate limiter, for RateLimitWaitSeconds overall.
+	RateLimitWaits       uint64    `json:"rateLimitWaits"`
+	RateLimitWaitSeconds float64   `json:"rateLimitWaitSeconds"`
+	Latency              Histogram `json:"latency"`
+}
+
+// Histogram is a latency distribution in seconds. Counts are cumulative: Counts[i] calls took at most Buckets[i].
+type Histogram struct {
+	Buckets []float64 `json:"buckets"`
+	Counts  []uint64  `json:"counts"`
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"`
+}
+
+// Snapshot returns a copy of the current metrics.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := MetricsSnapshot{
+		Endpoints:          make(map[Endpoint]map[string]EndpointSnapshot, len(m.endpoints)),
+		TokenRefreshes:     m.tokenRefreshes,
+		TokenRefreshErrors: m.tokenRefreshErrors,
+	}
+	for e, operations := range m.endpoints {
+		s.Endpoints[e] = make(map[string]EndpointSnapshot, len(operations))
+		for operation, em := range operations {
+			s.Endpoints[e][operation] = m.snapshot(em)
+		}
+	}
+	return s
+}
+
+// snapshot returns a copy of em. m.mu must be held.
+func (m *Metrics) snapshot(em *endpointMetrics) EndpointSnapshot {
+	errs := make(map[string]uint64, len(em.errors))
+	for category, n := range em.errors {
+		errs[category] = n
+	}
+	h := Histogram{Buckets: m.buckets, Counts: make([]uint64, len(m.buckets)), Sum: em.latencySum}
+	for i, n := range em.latencyCounts {
+		h.Count += n
+		if i < len(h.Counts) {
+			h.Counts[i] = h.Count
+		}
+	}
+	return EndpointSnapshot{
+		Requests:             em.requests,
+		Retries:              em.retries,
+		InFlight:             em.inFlight,
+		Errors:               errs,
+		RateLimitWaits:       em.rateLimitWaits,
+		RateLimitWaitSeconds: em.rateLimitWaited.Seconds(),
+		Latency:              h,
+	}
+}
+
+// Publish exports the metrics through expvar under name, e.g. at /debug/vars.
+// Like expvar.Publish, it panics if name is already registered.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return m.Snapshot() }))
+}
@@ -0,0 +1,65 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+func TestMetricsByOperation(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	metrics := grabexpress.NewMetrics()
+	client, err := srv.Client(grabexpress.WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	resp, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetDelivery(ctx, resp.DeliveryID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.Fail(grabexpresstest.Failure{Method: http.MethodGet, Status: http.StatusNotFound, Times: 1})
+	if _, err := client.GetDelivery(ctx, resp.DeliveryID); !errors.Is(err, grabexpress.ErrNotFound) {
+		t.Fatalf("GetDelivery() error = %v, want ErrNotFound", err)
+	}
+	if _, err := client.CreateQuotes(ctx, newCreateQuotesRequest()); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := metrics.Snapshot()
+	tests := []struct {
+		endpoint  grabexpress.Endpoint
+		operation string
+		requests  uint64
+		notFound  uint64
+	}{
+		{endpoint: grabexpress.EndpointDeliveries, operation: "CreateDelivery", requests: 1},
+		{endpoint: grabexpress.EndpointDeliveries, operation: "GetDelivery", requests: 3, notFound: 1},
+		{endpoint: grabexpress.EndpointQuotes, operation: "CreateQuotes", requests: 1},
+	}
+	for _, tt := range tests {
+		s, ok := snapshot.Endpoints[tt.endpoint][tt.operation]
+		if !ok {
+			t.Errorf("no metrics for %s %s", tt.endpoint, tt.operation)
+			continue
+		}
+		if s.Requests != tt.requests || s.Errors[grabexpress.CategoryNotFound] != tt.notFound || s.Latency.Count != tt.requests {
+			t.Errorf("%s %s: requests = %d, not found = %d, latency count = %d, want %d, %d, %d",
+				tt.endpoint, tt.operation, s.Requests, s.Errors[grabexpress.CategoryNotFound], s.Latency.Count,
+				tt.requests, tt.notFound, tt.requests)
+		}
+	}
+	if n := len(snapshot.Endpoints[grabexpress.EndpointDeliveries]); n != 2 {
+		t.Errorf("deliveries operations = %d, want 2", n)
+	}
+}
@@ -0,0 +1,69 @@
+package grabexpress
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Call is an API round-trip, as seen by middlewares.
+type Call struct {
+	// Operation is the name of the client method, e.g. "CreateDelivery".
+	Operation string
+	Endpoint  Endpoint
+	Method    string
+	Path      string
+	// Request is the typed request DTO, e.g. *CreateDeliveryRequest, or nil when the request has no body.
+	// It is encoded after the middlewares ran, so they may still modify it.
+	Request interface{}
+	// Response is the typed response DTO, e.g. *CreateDeliveryResponse, decoded once the round-trip succeeds.
+	Response DTO
+	// Header holds extra headers sent with every attempt.
+	Header http.Header
+
+	// Status and ResponseHeader describe the last HTTP response, if any.
+	Status         int
+	ResponseHeader http.Header
+	// Attempts is the number of HTTP requests sent, including retries.
+	Attempts int
+	// Start is when the round-trip started.
+	Start time.Time
+}
+
+// RoundTripFunc performs an API round-trip, returning an *Error on failure.
+type RoundTripFunc func(ctx context.Context, call *Call) error
+
+// Middleware wraps a RoundTripFunc, e.g. to log, measure or alter calls. A middleware may also answer a call
+// itself by filling call.Response without calling next.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware configures a GrabExpress API client to pass every API round-trip through middlewares.
+// The first middleware is the outermost one. Retries happen inside the chain.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) error {
+		c.middlewares = append(c.middlewares, middlewares...)
+		return nil
+	}
+}
+
+// chain wraps rt with the client middlewares.
+func (c *Client) chain(rt RoundTripFunc) RoundTripFunc {
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+	return rt
+}
+
+// operationOf returns the name of the client method sending method to path.
+func operationOf(method string, endpoint Endpoint) string {
+	if endpoint == EndpointQuotes {
+		return "CreateQuotes"
+	}
+	switch method {
+	case http.MethodPost:
+		return "CreateDelivery"
+	case http.MethodDelete:
+		return "CancelDelivery"
+	}
+	return "GetDelivery"
+}
@@ -209,6 +209,9 @@ type CreateQuotesResponse struct {
 
 // CreateDeliveryRequest ...
 type CreateDeliveryRequest struct {
+	// IdempotencyKey identifies the delivery creation client-side, in the IdempotencyStore; it is not sent to
+	// the API. Defaults to MerchantOrderID.
+	IdempotencyKey  string          `json:"-"`
 	MerchantOrderID string          `json:"merchantOrderID"`
 	ServiceType     ServiceType     `json:"serviceType"`
 	PaymentMethod   *PaymentMethod  `json:"paymentMethod,omitempty"`
@@ -242,3 +245,8 @@ type CancelDeliveryResponse struct {
 func (b *BaseDTO) SetRequestID(id string) {
 	b.RequestID = id
 }
+
+// retryable reports that quote requests have no side effect and can always be retried.
+func (r *CreateQuotesRequest) retryable() bool {
+	return true
+}
@@ -0,0 +1,237 @@
+package grabexpress
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrCurrencyMismatch is returned when combining amounts of different currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount in integer minor units of its Currency, e.g. cents for SGD or rupiah for IDR,
+// as given by Currency.Exponent.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// NewMoney ...
+func NewMoney(minorUnits int64, currency Currency) Money {
+	return Money{Amount: minorUnits, Currency: currency}
+}
+
+// MoneyFromFloat converts an amount in major units, as represented by the API, rounding it to the currency exponent.
+func MoneyFromFloat(amount float64, currency Currency) Money {
+	return Money{Amount: int64(math.Round(amount * math.Pow10(int(currency.Exponent)))), Currency: currency}
+}
+
+// ParseMoney parses a decimal amount in major units, e.g. "12.50". It fails rather than round
+// an amount more precise than the currency exponent.
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	exponent := int(currency.Exponent)
+	if trimmed := strings.TrimRight(fraction, "0"); len(trimmed) > exponent {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimals", amount, exponent)
+	}
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	if len(fraction) > exponent {
+		fraction = fraction[:exponent]
+	}
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || strings.ContainsAny(digits, "+-") {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// Float64 returns the amount in major units, as represented by the API.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / math.Pow10(int(m.Currency.Exponent))
+}
+
+// IsZero ...
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative ...
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// SameCurrency reports whether m and o are in the same currency.
+func (m Money) SameCurrency(o Money) bool {
+	return m.Currency.Code == o.Currency.Code && m.Currency.Exponent == o.Currency.Exponent
+}
+
+// Add ...
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub ...
+func (m Money) Sub(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Mul ...
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Cmp returns -1, 0 or +1 depending on whether m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) (int, error) {
+	if !m.SameCurrency(o) {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Equal ...
+func (m Money) Equal(o Money) bool {
+	return m.SameCurrency(o) && m.Amount == o.Amount
+}
+
+// String returns the amount with its currency code, e.g. "SGD 12.50".
+func (m Money) String() string {
+	return strings.TrimSpace(m.Currency.Code + " " + m.decimal(".", "", false))
+}
+
+// Format returns the amount with its currency symbol, using the separators of locale, e.g. "Rp15.000" for id_ID.
+func (m Money) Format(locale Locale) string {
+	f, ok := moneyFormats[locale]
+	if !ok {
+		f = moneyFormat{decimal: ".", group: ","}
+	}
+	symbol := m.Currency.Symbol
+	if symbol == "" {
+		symbol = m.Currency.Code
+	}
+	amount := m.decimal(f.decimal, f.group, f.indianGrouping)
+	sign := ""
+	if strings.HasPrefix(amount, "-") {
+		sign, amount = "-", amount[1:]
+	}
+	if f.symbolAfter {
+		return sign + amount + f.space + symbol
+	}
+	return sign + symbol + f.space + amount
+}
+
+// decimal renders the amount with the given separators.
+func (m Money) decimal(decimalSep, groupSep string, indianGrouping bool) string {
+	exponent := int(m.Currency.Exponent)
+	minor := m.Amount
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	digits := strconv.FormatInt(minor, 10)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-exponent], digits[len(digits)-exponent:]
+	whole = group(whole, groupSep, indianGrouping)
+	if exponent == 0 {
+		return sign + whole
+	}
+	return sign + whole + decimalSep + fraction
+}
+
+// group inserts sep between thousands, or between lakhs and crores with indianGrouping.
+func group(digits, sep string, indianGrouping bool) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	size := 3
+	if indianGrouping {
+		size = 2
+	}
+	var parts []string
+	for len(head) > size {
+		parts = append([]string{head[len(head)-size:]}, parts...)
+		head = head[:len(head)-size]
+	}
+	parts = append([]string{head}, parts...)
+	return strings.Join(append(parts, tail), sep)
+}
+
+type moneyFormat struct {
+	decimal        string
+	group          string
+	indianGrouping bool
+	symbolAfter    bool
+	space          string
+}
+
+var moneyFormats = map[Locale]moneyFormat{
+	LocaleBrasilEN:      {decimal: ".", group: ","},
+	LocaleBrasilPT:      {decimal: ",", group: ".", space: " "},
+	LocaleHongKongEN:    {decimal: ".", group: ","},
+	LocaleHongKongZH:    {decimal: ".", group: ","},
+	LocaleIndiaEN:       {decimal: ".", group: ",", indianGrouping: true},
+	LocaleIndiaHI:       {decimal: ".", group: ",", indianGrouping: true},
+	LocaleIndiaKN:       {decimal: ".", group: ",", indianGrouping: true},
+	LocaleIndiaMR:       {decimal: ".", group: ",", indianGrouping: true},
+	LocaleIndonesiaEN:   {decimal: ".", group: ","},
+	LocaleIndonesiaID:   {decimal: ",", group: "."},
+	LocaleMalaysiaEN:    {decimal: ".", group: ","},
+	LocaleMalaysiaMS:    {decimal: ".", group: ","},
+	LocaleMexicoEN:      {decimal: ".", group: ","},
+	LocaleMexicoMX:      {decimal: ".", group: ","},
+	LocalePhilippinesEN: {decimal: ".", group: ","},
+	LocaleSingaporeEN:   {decimal: ".", group: ","},
+	LocaleTaiwanZH:      {decimal: ".", group: ","},
+	LocaleThailandEN:    {decimal: ".", group: ","},
+	LocaleThailandTH:    {decimal: ".", group: ","},
+	LocaleVietnamEN:     {decimal: ".", group: ","},
+	LocaleVietnamVI:     {decimal: ",", group: ".", symbolAfter: true, space: " "},
+}
+
+// AmountMoney returns the quoted amount as Money.
+func (q QuoteBase) AmountMoney() Money {
+	return MoneyFromFloat(q.Amount, q.Currency)
+}
+
+// PriceMoney returns the package price as Money in currency.
+func (p Package) PriceMoney(currency Currency) Money {
+	return MoneyFromFloat(p.Price, currency)
+}
+
+// AmountMoney returns the amount to collect as Money in currency.
+func (c CashOnDelivery) AmountMoney(currency Currency) Money {
+	return MoneyFromFloat(c.Amount, currency)
+}
+
+// NewCashOnDelivery returns a CashOnDelivery collecting m.
+func NewCashOnDelivery(m Money) *CashOnDelivery {
+	return &CashOnDelivery{Amount: m.Float64()}
+}
@@ -0,0 +1,97 @@
+package grabexpress_test
+
+import (
+	"errors"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+var (
+	sgd = grabexpress.Currency{Code: "SGD", Symbol: "S$", Exponent: 2}
+	idr = grabexpress.Currency{Code: "IDR", Symbol: "Rp", Exponent: 0}
+	inr = grabexpress.Currency{Code: "INR", Symbol: "₹", Exponent: 2}
+	vnd = grabexpress.Currency{Code: "VND", Symbol: "₫", Exponent: 0}
+	brl = grabexpress.Currency{Code: "BRL", Symbol: "R$", Exponent: 2}
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency grabexpress.Currency
+		want     int64
+		wantErr  bool
+	}{
+		{"12.50", sgd, 1250, false},
+		{"12.5", sgd, 1250, false},
+		{"12", sgd, 1200, false},
+		{".5", sgd, 50, false},
+		{" -3.05 ", sgd, -305, false},
+		{"+7", sgd, 700, false},
+		{"12.500", sgd, 1250, false},
+		{"15000", idr, 15000, false},
+		{"15000.00", idr, 15000, false},
+		{"12.505", sgd, 0, true},
+		{"15000.5", idr, 0, true},
+		{"", sgd, 0, true},
+		{".", sgd, 0, true},
+		{"1,000", sgd, 0, true},
+		{"--1", sgd, 0, true},
+		{"1.-5", sgd, 0, true},
+	}
+	for _, tt := range tests {
+		m, err := grabexpress.ParseMoney(tt.amount, tt.currency)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMoney(%q) error = %v, want error %t", tt.amount, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (m.Amount != tt.want || m.Currency != tt.currency) {
+			t.Errorf("ParseMoney(%q) = %+v, want %d minor units", tt.amount, m, tt.want)
+		}
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		money  grabexpress.Money
+		locale grabexpress.Locale
+		want   string
+	}{
+		{grabexpress.NewMoney(123456, sgd), grabexpress.LocaleSingaporeEN, "S$1,234.56"},
+		{grabexpress.NewMoney(5, sgd), grabexpress.LocaleSingaporeEN, "S$0.05"},
+		{grabexpress.NewMoney(-1250, sgd), grabexpress.LocaleSingaporeEN, "-S$12.50"},
+		{grabexpress.NewMoney(15000, idr), grabexpress.LocaleIndonesiaID, "Rp15.000"},
+		{grabexpress.NewMoney(1234567890, inr), grabexpress.LocaleIndiaEN, "₹1,23,45,678.90"},
+		{grabexpress.NewMoney(24000, vnd), grabexpress.LocaleVietnamVI, "24.000 ₫"},
+		{grabexpress.NewMoney(123456, brl), grabexpress.LocaleBrasilPT, "R$ 1.234,56"},
+		{grabexpress.NewMoney(123456, sgd), "xx_XX", "S$1,234.56"},
+		{grabexpress.NewMoney(100, grabexpress.Currency{Code: "XYZ", Exponent: 2}), grabexpress.LocaleSingaporeEN, "XYZ1.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Format(tt.locale); got != tt.want {
+			t.Errorf("%v.Format(%s) = %q, want %q", tt.money, tt.locale, got, tt.want)
+		}
+	}
+	if got := grabexpress.NewMoney(-5, sgd).String(); got != "SGD -0.05" {
+		t.Errorf("String() = %q, want SGD -0.05", got)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a, b := grabexpress.NewMoney(1250, sgd), grabexpress.NewMoney(50, sgd)
+	if sum, err := a.Add(b); err != nil || sum.Amount != 1300 {
+		t.Errorf("Add() = %v, %v", sum, err)
+	}
+	if diff, err := b.Sub(a); err != nil || !diff.IsNegative() || diff.Amount != -1200 {
+		t.Errorf("Sub() = %v, %v", diff, err)
+	}
+	if cmp, err := a.Cmp(b); err != nil || cmp != 1 {
+		t.Errorf("Cmp() = %d, %v", cmp, err)
+	}
+	if _, err := a.Add(grabexpress.NewMoney(1, idr)); !errors.Is(err, grabexpress.ErrCurrencyMismatch) {
+		t.Errorf("Add() across currencies error = %v, want ErrCurrencyMismatch", err)
+	}
+	if m := grabexpress.MoneyFromFloat(0.1+0.2, sgd); m.Amount != 30 || m.Float64() != 0.3 {
+		t.Errorf("MoneyFromFloat(0.1+0.2) = %v", m)
+	}
+}
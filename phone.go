@@ -0,0 +1,141 @@
+package grabexpress
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidPhone is returned when a phone number is not valid in the country it is checked against.
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// phoneFormatting holds the characters commonly used to format phone numbers.
+var phoneFormatting = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "", " ", "")
+
+// phonePatterns caches the compiled Country.PhoneRegex, or their compile error, by pattern.
+var phonePatterns sync.Map
+
+// ValidPhone reports whether number is a valid phone number of c, in the national format GrabExpress
+// expects for Contact.Phone. Countries without a PhoneRegex accept any number, and countries whose PhoneRegex
+// is not a valid Go regular expression accept none.
+func (c Country) ValidPhone(number string) bool {
+	re, err := c.phonePattern()
+	return err == nil && (re == nil || re.MatchString(number))
+}
+
+// NationalPhone converts number to the national format GrabExpress expects for Contact.Phone.
+// number may be formatted, and given in national or international format, e.g. "+65 9123-4567".
+func (c Country) NationalPhone(number string) (string, error) {
+	digits, international, err := cleanPhone(number)
+	if err != nil {
+		return "", err
+	}
+	var candidates []string
+	switch {
+	case international:
+		if c.CallingCode == "" || !strings.HasPrefix(digits, c.CallingCode) {
+			return "", ErrInvalidPhone
+		}
+		candidates = subscriberCandidates(strings.TrimPrefix(digits, c.CallingCode))
+	default:
+		candidates = []string{digits}
+		if c.CallingCode != "" && strings.HasPrefix(digits, c.CallingCode) {
+			candidates = append(candidates, subscriberCandidates(strings.TrimPrefix(digits, c.CallingCode))...)
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate != "" && c.ValidPhone(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", ErrInvalidPhone
+}
+
+// NormalizePhone converts number to the E.164 format, e.g. "+6591234567".
+func (c Country) NormalizePhone(number string) (string, error) {
+	if c.CallingCode == "" {
+		return "", ErrInvalidPhone
+	}
+	national, err := c.NationalPhone(number)
+	if err != nil {
+		return "", err
+	}
+	return "+" + c.CallingCode + strings.TrimPrefix(national, "0"), nil
+}
+
+// PhoneCountries returns the supported countries number plausibly belongs to, ordered by country code.
+func PhoneCountries(number string) []Country {
+	codes := make([]string, 0, len(AllCountriesByISOCode))
+	for code := range AllCountriesByISOCode {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+
+	var countries []Country
+	for _, code := range codes {
+		country := AllCountriesByISOCode[CountryCode(code)]
+		if _, err := country.NationalPhone(number); err == nil {
+			countries = append(countries, country)
+		}
+	}
+	return countries
+}
+
+// NormalizePhone rewrites the contact phone number in the national format of country.
+func (c *Contact) NormalizePhone(country Country) error {
+	national, err := country.NationalPhone(c.Phone)
+	if err != nil {
+		return err
+	}
+	c.Phone = national
+	return nil
+}
+
+// cleanPhone strips formatting characters and the international prefix, + or 00, from number.
+func cleanPhone(number string) (digits string, international bool, err error) {
+	digits = phoneFormatting.Replace(strings.TrimSpace(number))
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits, international = digits[1:], true
+	case strings.HasPrefix(digits, "00"):
+		digits, international = digits[2:], true
+	}
+	if digits == "" {
+		return "", false, ErrInvalidPhone
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", false, ErrInvalidPhone
+		}
+	}
+	return digits, international, nil
+}
+
+// subscriberCandidates returns the national forms a number stripped of its calling code may take:
+// as is, or with the trunk prefix 0 some countries use.
+func subscriberCandidates(subscriber string) []string {
+	subscriber = strings.TrimPrefix(subscriber, "0")
+	return []string{subscriber, "0" + subscriber}
+}
+
+// phonePattern returns the compiled PhoneRegex, or nil when it is empty.
+func (c Country) phonePattern() (*regexp.Regexp, error) {
+	if c.PhoneRegex == "" {
+		return nil, nil
+	}
+	cached, ok := phonePatterns.Load(c.PhoneRegex)
+	if !ok {
+		re, err := regexp.Compile(c.PhoneRegex)
+		var compiled interface{} = re
+		if err != nil {
+			compiled = err
+		}
+		cached, _ = phonePatterns.LoadOrStore(c.PhoneRegex, compiled)
+	}
+	if err, ok := cached.(error); ok {
+		return nil, err
+	}
+	return cached.(*regexp.Regexp), nil
+}
@@ -0,0 +1,75 @@
+package grabexpress_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+func TestNationalPhone(t *testing.T) {
+	tests := []struct {
+		country  grabexpress.Country
+		number   string
+		national string
+		e164     string
+	}{
+		{grabexpress.CountrySingapore, "91234567", "91234567", "+6591234567"},
+		{grabexpress.CountrySingapore, "+65 9123-4567", "91234567", "+6591234567"},
+		{grabexpress.CountrySingapore, "0065 9123 4567", "91234567", "+6591234567"},
+		{grabexpress.CountrySingapore, "6591234567", "91234567", "+6591234567"},
+		{grabexpress.CountryPhilippines, "+63 917 123 4567", "09171234567", "+639171234567"},
+		{grabexpress.CountryPhilippines, "09171234567", "09171234567", "+639171234567"},
+		{grabexpress.CountryMalaysia, "+60 12-345 6789", "0123456789", "+60123456789"},
+		{grabexpress.CountrySingapore, "+63 917 123 4567", "", ""},
+		{grabexpress.CountrySingapore, "1234567", "", ""},
+		{grabexpress.CountrySingapore, "9123 ext 4567", "", ""},
+		{grabexpress.CountrySingapore, "+", "", ""},
+		{grabexpress.Country{Name: "PCRE", CallingCode: "65", PhoneRegex: `^(?=9)\d+$`}, "91234567", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.country.Name+" "+tt.number, func(t *testing.T) {
+			national, err := tt.country.NationalPhone(tt.number)
+			if tt.national == "" {
+				if !errors.Is(err, grabexpress.ErrInvalidPhone) {
+					t.Errorf("NationalPhone() = %q, %v, want ErrInvalidPhone", national, err)
+				}
+				return
+			}
+			if err != nil || national != tt.national {
+				t.Errorf("NationalPhone() = %q, %v, want %q", national, err, tt.national)
+			}
+			if e164, err := tt.country.NormalizePhone(tt.number); err != nil || e164 != tt.e164 {
+				t.Errorf("NormalizePhone() = %q, %v, want %q", e164, err, tt.e164)
+			}
+		})
+	}
+}
+
+func TestPhoneCountries(t *testing.T) {
+	tests := []struct {
+		number string
+		want   []grabexpress.CountryCode
+	}{
+		{"+65 9123 4567", []grabexpress.CountryCode{grabexpress.CountryCodeSingapore}},
+		{"+63 917 123 4567", []grabexpress.CountryCode{grabexpress.CountryCodePhilippines}},
+		{"not a number", nil},
+	}
+	for _, tt := range tests {
+		var got []grabexpress.CountryCode
+		for _, c := range grabexpress.PhoneCountries(tt.number) {
+			got = append(got, c.Code)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PhoneCountries(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestContactNormalizePhone(t *testing.T) {
+	c := grabexpress.Contact{Phone: "+65 9123 4567"}
+	if err := c.NormalizePhone(grabexpress.CountrySingapore); err != nil || c.Phone != "91234567" {
+		t.Errorf("NormalizePhone() = %q, %v, want 91234567", c.Phone, err)
+	}
+}
@@ -0,0 +1,230 @@
+package grabexpress
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults of a QuoteCache.
+const (
+	defaultQuoteCacheTTL       = time.Minute
+	defaultQuoteCacheSize      = 1024
+	defaultCoordinatePrecision = 4
+)
+
+// QuoteCache wraps Client.CreateQuotes with a size-bounded, time-limited cache.
+// Requests are keyed on their coordinates, rounded to a configurable precision, city codes, packages
+// and service type; free-text address fields are ignored. Concurrent identical requests share a single API call.
+type QuoteCache struct {
+	client    *Client
+	ttl       time.Duration
+	size      int
+	precision int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	flight  flightGroup
+	stats   QuoteCacheStats
+}
+
+type quoteCacheEntry struct {
+	key       string
+	resp      *CreateQuotesResponse
+	expiresAt time.Time
+}
+
+// QuoteCacheStats ...
+type QuoteCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Shared    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// QuoteCacheOption is the type of constructor options for NewQuoteCache(...).
+type QuoteCacheOption func(*QuoteCache)
+
+// WithQuoteCacheTTL configures how long a QuoteCache keeps quotes
+func WithQuoteCacheTTL(ttl time.Duration) QuoteCacheOption {
+	return func(c *QuoteCache) {
+		if ttl > 0 {
+			c.ttl = ttl
+		}
+	}
+}
+
+// WithQuoteCacheSize configures how many responses a QuoteCache keeps before evicting the least recently used
+func WithQuoteCacheSize(size int) QuoteCacheOption {
+	return func(c *QuoteCache) {
+		if size > 0 {
+			c.size = size
+		}
+	}
+}
+
+// WithCoordinatePrecision configures the number of decimals coordinates are rounded to when keying a QuoteCache.
+// The default of 4 decimals treats points about 11 meters apart as identical.
+func WithCoordinatePrecision(decimals int) QuoteCacheOption {
+	return func(c *QuoteCache) {
+		if decimals >= 0 {
+			c.precision = decimals
+		}
+	}
+}
+
+// NewQuoteCache constructs a QuoteCache requesting quotes with client.
+func NewQuoteCache(client *Client, options ...QuoteCacheOption) *QuoteCache {
+	c := &QuoteCache{
+		client:    client,
+		ttl:       defaultQuoteCacheTTL,
+		size:      defaultQuoteCacheSize,
+		precision: defaultCoordinatePrecision,
+		entries:   map[string]*list.Element{},
+		lru:       list.New(),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// CreateQuotes returns the cached quotes for req, requesting them when missing or expired.
+// Errors are not cached.
+func (c *QuoteCache) CreateQuotes(ctx context.Context, req *CreateQuotesRequest) (*CreateQuotesResponse, error) {
+	key, err := c.key(req)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if resp, ok := c.get(key); ok {
+		return resp, nil
+	}
+	value, shared, err := c.flight.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		resp, err := c.client.CreateQuotes(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		c.put(key, resp)
+		return resp, nil
+	})
+	c.mu.Lock()
+	if shared {
+		c.stats.Shared++
+	} else {
+		c.stats.Misses++
+	}
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return copyQuotesResponse(value.(*CreateQuotesResponse)), nil
+}
+
+// Stats returns the cache statistics.
+func (c *QuoteCache) Stats() QuoteCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// Purge empties the cache.
+func (c *QuoteCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+func (c *QuoteCache) get(key string) (*CreateQuotesResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*quoteCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+	return copyQuotesResponse(entry.resp), true
+}
+
+func (c *QuoteCache) put(key string, resp *CreateQuotesResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &quoteCacheEntry{key: key, resp: copyQuotesResponse(resp), expiresAt: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*quoteCacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// key returns the canonical hash of the request fields that affect quotes.
+func (c *QuoteCache) key(req *CreateQuotesRequest) (string, error) {
+	type waypoint struct {
+		CityCode    *string     `json:"cityCode"`
+		Coordinates Coordinates `json:"coordinates"`
+	}
+	canonical := struct {
+		ServiceType *ServiceType `json:"serviceType"`
+		Packages    []string     `json:"packages"`
+		Origin      waypoint     `json:"origin"`
+		Destination waypoint     `json:"destination"`
+	}{
+		ServiceType: req.ServiceType,
+		Origin:      waypoint{req.Origin.CityCode, c.round(req.Origin.Coordinates)},
+		Destination: waypoint{req.Destination.CityCode, c.round(req.Destination.Coordinates)},
+	}
+	for _, p := range req.Packages {
+		bb, err := json.Marshal(p)
+		if err != nil {
+			return "", err
+		}
+		canonical.Packages = append(canonical.Packages, string(bb))
+	}
+	sort.Strings(canonical.Packages)
+	bb, err := json.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bb)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *QuoteCache) round(coordinates Coordinates) Coordinates {
+	scale := math.Pow10(c.precision)
+	return Coordinates{
+		Latitude:  math.Round(coordinates.Latitude*scale) / scale,
+		Longitude: math.Round(coordinates.Longitude*scale) / scale,
+	}
+}
+
+// copyQuotesResponse returns a copy of resp that callers may modify without altering the cache.
+func copyQuotesResponse(resp *CreateQuotesResponse) *CreateQuotesResponse {
+	dup := *resp
+	dup.Quotes = append([]QuoteBase(nil), resp.Quotes...)
+	dup.Packages = append([]Package(nil), resp.Packages...)
+	return &dup
+}
@@ -0,0 +1,166 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+const quotesPath = "/v1/deliveries/quotes"
+
+func newCreateQuotesRequest() *grabexpress.CreateQuotesRequest {
+	req := newCreateDeliveryRequest("")
+	return &grabexpress.CreateQuotesRequest{
+		ServiceType: &req.ServiceType,
+		Packages:    req.Packages,
+		Origin:      req.Origin,
+		Destination: req.Destination,
+	}
+}
+
+func newQuoteCache(t *testing.T, options ...grabexpress.QuoteCacheOption) (*grabexpress.QuoteCache, *grabexpresstest.Server) {
+	t.Helper()
+	srv := grabexpresstest.NewServer()
+	t.Cleanup(srv.Close)
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grabexpress.NewQuoteCache(client, options...), srv
+}
+
+func TestQuoteCacheKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*grabexpress.CreateQuotesRequest)
+		hit    bool
+	}{
+		{name: "identical", modify: func(*grabexpress.CreateQuotesRequest) {}, hit: true},
+		{name: "different address", modify: func(r *grabexpress.CreateQuotesRequest) { r.Origin.Address = "Raffles Place MRT" }, hit: true},
+		{name: "coordinates within precision", modify: func(r *grabexpress.CreateQuotesRequest) { r.Origin.Coordinates.Latitude += 0.00001 }, hit: true},
+		{name: "coordinates beyond precision", modify: func(r *grabexpress.CreateQuotesRequest) { r.Origin.Coordinates.Latitude += 0.001 }},
+		{name: "different service type", modify: func(r *grabexpress.CreateQuotesRequest) {
+			serviceType := grabexpress.ServiceTypeSameDay
+			r.ServiceType = &serviceType
+		}},
+		{name: "different packages", modify: func(r *grabexpress.CreateQuotesRequest) {
+			r.Packages = append([]grabexpress.Package(nil), r.Packages...)
+			r.Packages[0].Quantity = 2
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := newQuoteCache(t)
+			ctx := context.Background()
+			if _, err := cache.CreateQuotes(ctx, newCreateQuotesRequest()); err != nil {
+				t.Fatal(err)
+			}
+			req := newCreateQuotesRequest()
+			tt.modify(req)
+			if _, err := cache.CreateQuotes(ctx, req); err != nil {
+				t.Fatal(err)
+			}
+			if hit := cache.Stats().Hits == 1; hit != tt.hit {
+				t.Errorf("hit = %v, want %v", hit, tt.hit)
+			}
+		})
+	}
+}
+
+func TestQuoteCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, _ := newQuoteCache(t, grabexpress.WithQuoteCacheSize(2))
+	ctx := context.Background()
+	requests := make([]*grabexpress.CreateQuotesRequest, 3)
+	for i := range requests {
+		requests[i] = newCreateQuotesRequest()
+		requests[i].Destination.Coordinates.Latitude += float64(i) / 100
+	}
+	for _, i := range []int{0, 1, 0, 2} {
+		if _, err := cache.CreateQuotes(ctx, requests[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := cache.Stats()
+	if stats.Evictions != 1 || stats.Entries != 2 {
+		t.Fatalf("stats = %+v, want 1 eviction and 2 entries", stats)
+	}
+	// requests[1] was the least recently used, so requests[0] is still cached.
+	if _, err := cache.CreateQuotes(ctx, requests[0]); err != nil {
+		t.Fatal(err)
+	}
+	if hits := cache.Stats().Hits; hits != 2 {
+		t.Errorf("hits = %d, want 2", hits)
+	}
+	if _, err := cache.CreateQuotes(ctx, requests[1]); err != nil {
+		t.Fatal(err)
+	}
+	if misses := cache.Stats().Misses; misses != 4 {
+		t.Errorf("misses = %d, want 4", misses)
+	}
+}
+
+func TestQuoteCacheExpiresEntries(t *testing.T) {
+	cache, srv := newQuoteCache(t, grabexpress.WithQuoteCacheTTL(10*time.Millisecond))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := cache.CreateQuotes(ctx, newCreateQuotesRequest()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := cache.CreateQuotes(ctx, newCreateQuotesRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRequests(srv, http.MethodPost, quotesPath); n != 2 {
+		t.Errorf("quote requests = %d, want 2", n)
+	}
+}
+
+func TestQuoteCacheSharesConcurrentRequests(t *testing.T) {
+	cache, srv := newQuoteCache(t)
+	srv.SetLatency(50 * time.Millisecond)
+
+	// The first caller gives up early, which must not fail the callers sharing its request.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.CreateQuotes(ctx, newCreateQuotesRequest())
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.CreateQuotes(context.Background(), newCreateQuotesRequest())
+			errs <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller error = %v, want context.Canceled", err)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("shared caller error = %v", err)
+		}
+	}
+	if n := countRequests(srv, http.MethodPost, quotesPath); n != 1 {
+		t.Errorf("quote requests = %d, want 1", n)
+	}
+	if shared := cache.Stats().Shared; shared != 4 {
+		t.Errorf("shared = %d, want 4", shared)
+	}
+}
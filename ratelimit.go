@@ -0,0 +1,152 @@
+package grabexpress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint identifies a group of GrabExpress API endpoints sharing a rate limit budget.
+type Endpoint string
+
+// Endpoint enum
+const (
+	EndpointQuotes     Endpoint = "quotes"
+	EndpointDeliveries Endpoint = "deliveries"
+)
+
+// endpointOf returns the endpoint group of an API path.
+func endpointOf(path string) Endpoint {
+	if strings.HasPrefix(path, "/v1/deliveries/quotes") {
+		return EndpointQuotes
+	}
+	return EndpointDeliveries
+}
+
+// Rate is a token-bucket budget: PerSecond requests per second on average, in bursts of up to Burst requests.
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// ErrInvalidRateLimit is returned by WithRateLimit when a rate is malformed.
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// minRateFraction bounds how far the adaptive slowdown may reduce a configured rate.
+const minRateFraction = 0.125
+
+// WithRateLimit configures a GrabExpress API client to throttle its own requests, with a separate budget
+// per endpoint; endpoints without a rate are not limited. The budgets are shared by all goroutines using the client.
+// Requests wait for their turn unless ctx would expire first, in which case they fail immediately with an error
+// matching ErrRateLimited. When the API answers 429 Too Many Requests, the rate of the endpoint is halved and
+// requests are held back for the Retry-After delay; it then recovers gradually with successful responses.
+func WithRateLimit(rates map[Endpoint]Rate) ClientOption {
+	return func(c *Client) error {
+		limiter := &rateLimiter{buckets: map[Endpoint]*adaptiveBucket{}}
+		for endpoint, rate := range rates {
+			if rate.PerSecond <= 0 || rate.Burst < 0 {
+				return fmt.Errorf("%w: %s: %+v", ErrInvalidRateLimit, endpoint, rate)
+			}
+			limiter.buckets[endpoint] = &adaptiveBucket{
+				bucket: newTokenBucket(rate.PerSecond, rate.Burst),
+				max:    rate.PerSecond,
+				rate:   rate.PerSecond,
+			}
+		}
+		c.rateLimiter = limiter
+		return nil
+	}
+}
+
+// rateLimiter holds the per-endpoint budgets of a client. A nil rateLimiter allows everything.
+type rateLimiter struct {
+	buckets map[Endpoint]*adaptiveBucket
+}
+
+// wait blocks until a request to endpoint is allowed, and returns how long it waited.
+func (l *rateLimiter) wait(ctx context.Context, endpoint Endpoint) (time.Duration, *Error) {
+	if l == nil {
+		return 0, nil
+	}
+	b, ok := l.buckets[endpoint]
+	if !ok {
+		return 0, nil
+	}
+	return b.wait(ctx)
+}
+
+// observe adapts the budget of endpoint to the response of the API.
+func (l *rateLimiter) observe(endpoint Endpoint, resp *http.Response) {
+	if l == nil || resp == nil {
+		return
+	}
+	if b, ok := l.buckets[endpoint]; ok {
+		b.observe(resp, time.Now())
+	}
+}
+
+// adaptiveBucket is a token bucket whose rate decreases multiplicatively when the API pushes back,
+// and increases additively as requests succeed again.
+type adaptiveBucket struct {
+	bucket *tokenBucket
+	max    float64
+
+	mu     sync.Mutex
+	rate   float64
+	paused time.Time
+}
+
+func (b *adaptiveBucket) wait(ctx context.Context) (time.Duration, *Error) {
+	delay := b.bucket.reserve()
+	b.mu.Lock()
+	if pause := time.Until(b.paused); pause > delay {
+		delay = pause
+	}
+	b.mu.Unlock()
+	if delay <= 0 {
+		return 0, nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		b.bucket.cancel()
+		return 0, &Error{
+			Message:    fmt.Sprintf("client-side rate limit: next slot in %s, after the context deadline", delay.Round(time.Millisecond)),
+			RetryAfter: delay,
+			Err:        ErrRateLimited,
+		}
+	}
+	start := time.Now()
+	if err := sleep(ctx, delay); err != nil {
+		b.bucket.cancel()
+		return time.Since(start), wrapError(err)
+	}
+	return delay, nil
+}
+
+func (b *adaptiveBucket) observe(resp *http.Response, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delay := retryAfter(resp.Header, now)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		b.rate /= 2
+		if min := b.max * minRateFraction; b.rate < min {
+			b.rate = min
+		}
+		if delay == 0 {
+			delay = time.Duration(float64(time.Second) / b.rate)
+		}
+	case resp.StatusCode < http.StatusBadRequest && b.rate < b.max:
+		b.rate += b.max * minRateFraction / 4
+		if b.rate > b.max {
+			b.rate = b.max
+		}
+	}
+	if until := now.Add(delay); delay > 0 && until.After(b.paused) {
+		b.paused = until
+	}
+	b.bucket.setRate(b.rate)
+}
@@ -0,0 +1,88 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+func TestRateLimitHonorsRetryAfter(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client(grabexpress.WithRateLimit(map[grabexpress.Endpoint]grabexpress.Rate{
+		grabexpress.EndpointDeliveries: {PerSecond: 100, Burst: 10},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	resp, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Fail(grabexpresstest.Failure{Method: http.MethodGet, Status: http.StatusTooManyRequests, RetryAfter: time.Second, Times: 1})
+	_, err = client.GetDelivery(ctx, resp.DeliveryID)
+	var apiErr *grabexpress.Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, grabexpress.ErrRateLimited) || apiErr.RetryAfter != time.Second {
+		t.Fatalf("GetDelivery() error = %v, want ErrRateLimited after 1s", err)
+	}
+
+	// The client holds requests back for the Retry-After delay, failing those that cannot wait that long.
+	sent := len(srv.Requests())
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetDelivery(short, resp.DeliveryID); !errors.Is(err, grabexpress.ErrRateLimited) {
+		t.Fatalf("GetDelivery() during the pause error = %v, want ErrRateLimited", err)
+	}
+	if n := len(srv.Requests()); n != sent {
+		t.Fatalf("sent %d requests during the pause", n-sent)
+	}
+	start := time.Now()
+	if _, err := client.GetDelivery(ctx, resp.DeliveryID); err != nil {
+		t.Fatalf("GetDelivery() after the pause error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("GetDelivery() was held back %s, want the rest of the Retry-After delay", elapsed)
+	}
+}
+
+func TestRateLimitPerEndpoint(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client(grabexpress.WithRateLimit(map[grabexpress.Endpoint]grabexpress.Rate{
+		grabexpress.EndpointQuotes: {PerSecond: 1, Burst: 1},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := client.CreateQuotes(ctx, newCreateQuotesRequest()); err != nil {
+		t.Fatal(err)
+	}
+	// The quotes budget is spent until ctx expires, while deliveries have no limit.
+	if _, err := client.CreateQuotes(ctx, newCreateQuotesRequest()); !errors.Is(err, grabexpress.ErrRateLimited) {
+		t.Errorf("CreateQuotes() error = %v, want ErrRateLimited", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := client.CreateDelivery(ctx, newCreateDeliveryRequest(fmt.Sprintf("ORDER-%d", i))); err != nil {
+			t.Errorf("CreateDelivery() error = %v", err)
+		}
+	}
+}
+
+func TestWithRateLimitRejectsInvalidRates(t *testing.T) {
+	for _, rate := range []grabexpress.Rate{{}, {PerSecond: -1, Burst: 1}, {PerSecond: 1, Burst: -1}} {
+		_, err := grabexpress.NewClient(grabexpress.WithRateLimit(map[grabexpress.Endpoint]grabexpress.Rate{grabexpress.EndpointQuotes: rate}))
+		if !errors.Is(err, grabexpress.ErrInvalidRateLimit) {
+			t.Errorf("rate %+v: error = %v, want ErrInvalidRateLimit", rate, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package grabexpress
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RedactFunc replaces a JSON value by a masked version. The value is decoded as by encoding/json into an
+// interface{}: a string, float64, bool, nil, map[string]interface{} or []interface{}.
+type RedactFunc func(value interface{}) interface{}
+
+// redacted replaces masked values.
+const redacted = "[REDACTED]"
+
+// Mask replaces any value.
+func Mask(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	return redacted
+}
+
+// MaskPhone keeps the last 3 digits of a phone number.
+func MaskPhone(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok || len(s) <= 3 {
+		return Mask(value)
+	}
+	return strings.Repeat("*", len(s)-3) + s[len(s)-3:]
+}
+
+// MaskEmail keeps the first character of the local part and the domain of an email address.
+func MaskEmail(value interface{}) interface{} {
+	s, ok := value.(string)
+	at := strings.LastIndex(s, "@")
+	if !ok || at < 1 {
+		return Mask(value)
+	}
+	return s[:1] + "***" + s[at:]
+}
+
+// Redactor masks personal data in API payloads before they are logged.
+// Rules are keyed by JSON field paths such as "sender.phone", which match the end of the path of a field:
+// "origin.address" matches both "origin.address" and "quote.origin.address". Arrays are transparent.
+// Rules must be set before the Redactor is used.
+type Redactor struct {
+	rules map[string]RedactFunc
+}
+
+// NewRedactor constructs a Redactor masking the Contact, Courier and Waypoint personal data, and pickup PINs.
+func NewRedactor() *Redactor {
+	r := &Redactor{rules: map[string]RedactFunc{"pickupPin": Mask}}
+	for _, contact := range []string{"sender", "recipient"} {
+		r.Set(contact+".firstName", Mask)
+		r.Set(contact+".lastName", Mask)
+		r.Set(contact+".email", MaskEmail)
+		r.Set(contact+".phone", MaskPhone)
+		r.Set(contact+".instruction", Mask)
+	}
+	r.Set("courier.name", Mask)
+	r.Set("courier.phone", MaskPhone)
+	r.Set("courier.pictureURL", Mask)
+	r.Set("courier.coordinates", Mask)
+	r.Set("courier.vehicle.licensePlate", Mask)
+	for _, waypoint := range []string{"origin", "destination"} {
+		r.Set(waypoint+".address", Mask)
+		r.Set(waypoint+".keywords", Mask)
+		r.Set(waypoint+".coordinates", Mask)
+		r.Set(waypoint+".extra", Mask)
+	}
+	return r
+}
+
+// Set masks the fields matching path with fn. A nil fn removes the rule.
+func (r *Redactor) Set(path string, fn RedactFunc) {
+	if fn == nil {
+		delete(r.rules, path)
+		return
+	}
+	r.rules[path] = fn
+}
+
+// Redact returns the JSON encoding of v with the matching fields masked.
+func (r *Redactor) Redact(v interface{}) ([]byte, error) {
+	bb, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := json.Unmarshal(bb, &tree); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r.walk(tree, nil))
+}
+
+func (r *Redactor) walk(v interface{}, path []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			if fn := r.match(childPath); fn != nil {
+				v[key] = fn(child)
+			} else {
+				v[key] = r.walk(child, childPath)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.walk(child, path)
+		}
+	}
+	return v
+}
+
+// match returns the rule of the longest path matching the end of path.
+func (r *Redactor) match(path []string) RedactFunc {
+	for i := range path {
+		if fn, ok := r.rules[strings.Join(path[i:], ".")]; ok {
+			return fn
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package grabexpress_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+func TestMasks(t *testing.T) {
+	tests := []struct {
+		name  string
+		mask  grabexpress.RedactFunc
+		value interface{}
+		want  interface{}
+	}{
+		{name: "string", mask: grabexpress.Mask, value: "Riley", want: "[REDACTED]"},
+		{name: "number", mask: grabexpress.Mask, value: 1.28, want: "[REDACTED]"},
+		{name: "empty", mask: grabexpress.Mask, value: "", want: ""},
+		{name: "null", mask: grabexpress.Mask, value: nil, want: nil},
+		{name: "phone", mask: grabexpress.MaskPhone, value: "+6591234567", want: "********567"},
+		{name: "short phone", mask: grabexpress.MaskPhone, value: "123", want: "[REDACTED]"},
+		{name: "phone number", mask: grabexpress.MaskPhone, value: 91234567.0, want: "[REDACTED]"},
+		{name: "email", mask: grabexpress.MaskEmail, value: "riley@example.com", want: "r***@example.com"},
+		{name: "email without local part", mask: grabexpress.MaskEmail, value: "@example.com", want: "[REDACTED]"},
+		{name: "not an email", mask: grabexpress.MaskEmail, value: "riley", want: "[REDACTED]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mask(tt.value); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	withoutCoordinates := grabexpress.NewRedactor()
+	withoutCoordinates.Set("origin.coordinates", nil)
+	withMerchantOrderID := grabexpress.NewRedactor()
+	withMerchantOrderID.Set("merchantOrderID", grabexpress.Mask)
+
+	tests := []struct {
+		name     string
+		redactor *grabexpress.Redactor
+		in       string
+		want     string
+	}{
+		{
+			name:     "contact",
+			redactor: grabexpress.NewRedactor(),
+			in:       `{"merchantOrderID":"ORDER-1","sender":{"firstName":"Sam","email":"sam@example.com","phone":"91234567"}}`,
+			want:     `{"merchantOrderID":"ORDER-1","sender":{"firstName":"[REDACTED]","email":"s***@example.com","phone":"*****567"}}`,
+		},
+		{
+			name:     "nested path",
+			redactor: grabexpress.NewRedactor(),
+			in:       `{"quote":{"origin":{"address":"1 Raffles Place","cityCode":"SIN"}}}`,
+			want:     `{"quote":{"origin":{"address":"[REDACTED]","cityCode":"SIN"}}}`,
+		},
+		{
+			name:     "arrays",
+			redactor: grabexpress.NewRedactor(),
+			in:       `{"deliveries":[{"pickupPin":"1234"},{"pickupPin":"5678"}]}`,
+			want:     `{"deliveries":[{"pickupPin":"[REDACTED]"},{"pickupPin":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "whole object",
+			redactor: grabexpress.NewRedactor(),
+			in:       `{"courier":{"coordinates":{"latitude":1.28,"longitude":103.85},"rating":4.9}}`,
+			want:     `{"courier":{"coordinates":"[REDACTED]","rating":4.9}}`,
+		},
+		{
+			name:     "removed rule",
+			redactor: withoutCoordinates,
+			in:       `{"origin":{"coordinates":{"latitude":1.28}},"destination":{"coordinates":{"latitude":1.29}}}`,
+			want:     `{"origin":{"coordinates":{"latitude":1.28}},"destination":{"coordinates":"[REDACTED]"}}`,
+		},
+		{
+			name:     "added rule",
+			redactor: withMerchantOrderID,
+			in:       `{"merchantOrderID":"ORDER-1"}`,
+			want:     `{"merchantOrderID":"[REDACTED]"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bb, err := tt.redactor.Redact(json.RawMessage(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(bb, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s, want %s", bb, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorDoesNotAlterInput(t *testing.T) {
+	req := newCreateDeliveryRequest("ORDER-1")
+	if _, err := grabexpress.NewRedactor().Redact(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Recipient.Phone != "98765432" || req.Origin.Address != "1 Raffles Place" {
+		t.Errorf("Redact() altered its input: %+v", req)
+	}
+}
@@ -0,0 +1,165 @@
+package grabexpress
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries requests that failed transiently: connection resets,
+// timeouts, 429 Too Many Requests and 502/503/504 responses.
+// Only requests that are safe to repeat are retried: GET and DELETE requests, and quotes. Delivery creations
+// are only retried when the API certainly did not process them: 429 responses, unreachable servers and
+// failed token fetches. Their other failures are left to the IdempotencyStore, see WithIdempotencyStore.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Defaults to 200ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts. Defaults to 10s.
+	MaxBackoff time.Duration
+	// Multiplier is the factor by which the delay grows after each attempt. Defaults to 2.
+	Multiplier float64
+	// Jitter is the fraction of the delay that is randomized, between 0 and 1.
+	Jitter float64
+	// MaxElapsedTime bounds the total time spent across all attempts. Zero means only ctx bounds it.
+	MaxElapsedTime time.Duration
+}
+
+// DefaultRetryPolicy is a sensible retry policy for most integrations.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	MaxElapsedTime: 30 * time.Second,
+}
+
+// ErrInvalidRetryPolicy is returned by WithRetryPolicy when the policy is malformed.
+var ErrInvalidRetryPolicy = errors.New("invalid retry policy")
+
+// WithRetryPolicy configures a GrabExpress API client to retry transient failures according to policy
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		if policy.MaxAttempts < 0 || policy.InitialBackoff < 0 || policy.MaxBackoff < 0 ||
+			policy.MaxElapsedTime < 0 || policy.Jitter < 0 || policy.Jitter > 1 ||
+			(policy.Multiplier != 0 && policy.Multiplier < 1) {
+			return ErrInvalidRetryPolicy
+		}
+		if policy.InitialBackoff == 0 {
+			policy.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+		}
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+		}
+		if policy.Multiplier == 0 {
+			policy.Multiplier = DefaultRetryPolicy.Multiplier
+		}
+		c.retryPolicy = policy
+		return nil
+	}
+}
+
+// backoff returns the delay to wait after the given failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if max := float64(p.MaxBackoff); delay > max {
+		delay = max
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// allows reports whether another attempt after delay still fits within the policy and ctx deadlines.
+func (p RetryPolicy) allows(ctx context.Context, start time.Time, delay time.Duration) bool {
+	next := time.Now().Add(delay)
+	if p.MaxElapsedTime > 0 && next.After(start.Add(p.MaxElapsedTime)) {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && next.After(deadline) {
+		return false
+	}
+	return true
+}
+
+// retryableRequest is implemented by request DTOs that know whether they may be sent more than once.
+type retryableRequest interface {
+	retryable() bool
+}
+
+func isRetryableRequest(method string, apiReq interface{}) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	r, ok := apiReq.(retryableRequest)
+	return ok && r.retryable()
+}
+
+// isUnprocessed reports whether a failed attempt certainly did not reach the API, or was turned down
+// before being processed, so that even a non-idempotent request may be sent again.
+func isUnprocessed(resp *http.Response, apiErr *Error) bool {
+	if apiErr == nil {
+		return resp.StatusCode == http.StatusTooManyRequests
+	}
+	return apiErr.auth || (apiErr.transport && errors.Is(apiErr.Err, syscall.ECONNREFUSED))
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isRetryableTransportError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an HTTP date.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package grabexpress_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+// fastRetries retries quickly, so that tests do not wait for backoffs.
+var fastRetries = grabexpress.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func TestRetryTransientFailures(t *testing.T) {
+	tests := []struct {
+		status   int
+		times    int
+		wantSent int
+		wantErr  bool
+	}{
+		{status: http.StatusServiceUnavailable, times: 1, wantSent: 2},
+		{status: http.StatusBadGateway, times: 2, wantSent: 3},
+		{status: http.StatusServiceUnavailable, times: 3, wantSent: 3, wantErr: true},
+		{status: http.StatusInternalServerError, times: 1, wantSent: 1, wantErr: true},
+		{status: http.StatusBadRequest, times: 1, wantSent: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := grabexpresstest.NewServer()
+			defer srv.Close()
+			client, err := srv.Client(grabexpress.WithRetryPolicy(fastRetries))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			resp, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.Fail(grabexpresstest.Failure{Method: http.MethodGet, Status: tt.status, Times: tt.times})
+			_, err = client.GetDelivery(ctx, resp.DeliveryID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDelivery() error = %v, want error %t", err, tt.wantErr)
+			}
+			if n := countRequests(srv, http.MethodGet, "/v1/deliveries/"+resp.DeliveryID); n != tt.wantSent {
+				t.Errorf("sent %d requests, want %d", n, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client(grabexpress.WithRetryPolicy(fastRetries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	resp, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Fail(grabexpresstest.Failure{Method: http.MethodGet, Status: http.StatusTooManyRequests, RetryAfter: time.Second, Times: 1})
+	start := time.Now()
+	if _, err := client.GetDelivery(ctx, resp.DeliveryID); err != nil {
+		t.Fatalf("GetDelivery() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("GetDelivery() retried after %s, want the Retry-After delay", elapsed)
+	}
+	if n := countRequests(srv, http.MethodGet, "/v1/deliveries/"+resp.DeliveryID); n != 2 {
+		t.Errorf("sent %d requests, want 2", n)
+	}
+}
@@ -0,0 +1,186 @@
+package grabexpress
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrNoQuoteSelected is returned when no quote satisfies a SelectionPolicy.
+var ErrNoQuoteSelected = errors.New("no quote satisfies the selection policy")
+
+// QuoteScorer scores a quote; a lower score is better.
+type QuoteScorer func(q QuoteBase) float64
+
+// Predefined QuoteScorers.
+var (
+	// ScoreByAmount prefers the cheapest quote.
+	ScoreByAmount QuoteScorer = func(q QuoteBase) float64 {
+		return q.Amount
+	}
+	// ScoreByDropoff prefers the quote with the earliest estimated dropoff. Quotes without estimate come last.
+	ScoreByDropoff QuoteScorer = func(q QuoteBase) float64 {
+		if dropoff := estimatedDropoff(q); dropoff != nil {
+			return float64(dropoff.Unix())
+		}
+		return float64(1<<63 - 1)
+	}
+)
+
+// SelectionPolicy describes how to pick a quote among those returned by CreateQuotes.
+// Quotes violating a constraint are rejected; the others are ranked by ServiceTypes preference, then by Score.
+type SelectionPolicy struct {
+	// ServiceTypes restricts the acceptable service types, the first being the most preferred.
+	// Empty means any service type, with no preference.
+	ServiceTypes []ServiceType
+	// Score ranks the acceptable quotes. Defaults to ScoreByAmount.
+	Score QuoteScorer
+	// MaxAmount rejects the quotes costing more, when set.
+	MaxAmount *Money
+	// LatestDropoff rejects the quotes whose estimated dropoff is later, or unknown, when set.
+	LatestDropoff *time.Time
+}
+
+// CheapestPolicy selects the cheapest quote.
+func CheapestPolicy() SelectionPolicy {
+	return SelectionPolicy{Score: ScoreByAmount}
+}
+
+// FastestPolicy selects the quote with the earliest estimated dropoff.
+func FastestPolicy() SelectionPolicy {
+	return SelectionPolicy{Score: ScoreByDropoff}
+}
+
+// RankedQuote is a quote as evaluated by a SelectionPolicy.
+type RankedQuote struct {
+	Quote QuoteBase
+	// Rank is the position among the accepted quotes, starting at 1, or 0 when rejected.
+	Rank     int
+	Score    float64
+	Rejected bool
+	// Reasons explains why the quote was rejected, or how it ranked.
+	Reasons []string
+}
+
+// Selection is the outcome of applying a SelectionPolicy.
+type Selection struct {
+	// Quote is the selected quote.
+	Quote QuoteBase
+	// Quotes lists every quote, accepted ones first by rank, then rejected ones.
+	Quotes []RankedQuote
+}
+
+// Explain describes how every quote was evaluated, one line per quote.
+func (s *Selection) Explain() []string {
+	lines := make([]string, len(s.Quotes))
+	for i, r := range s.Quotes {
+		state := fmt.Sprintf("#%d", r.Rank)
+		if r.Rejected {
+			state = "rejected"
+		}
+		lines[i] = fmt.Sprintf("%s %s (%s): %s %v", state, r.Quote.Service.Name, r.Quote.Service.Type,
+			r.Quote.AmountMoney(), r.Reasons)
+	}
+	return lines
+}
+
+// Rank evaluates quotes against the policy, without failing when none is acceptable.
+func (p SelectionPolicy) Rank(quotes []QuoteBase) []RankedQuote {
+	score := p.Score
+	if score == nil {
+		score = ScoreByAmount
+	}
+	ranked := make([]RankedQuote, len(quotes))
+	for i, q := range quotes {
+		r := RankedQuote{Quote: q, Score: score(q)}
+		r.Reasons = p.rejections(q)
+		r.Rejected = len(r.Reasons) > 0
+		ranked[i] = r
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Rejected != b.Rejected {
+			return !a.Rejected
+		}
+		if pa, pb := p.preference(a.Quote.Service.Type), p.preference(b.Quote.Service.Type); pa != pb {
+			return pa < pb
+		}
+		return a.Score < b.Score
+	})
+	rank := 0
+	for i := range ranked {
+		r := &ranked[i]
+		if r.Rejected {
+			continue
+		}
+		rank++
+		r.Rank = rank
+		r.Reasons = append(r.Reasons, fmt.Sprintf("score %g", r.Score))
+		if len(p.ServiceTypes) > 1 {
+			r.Reasons = append(r.Reasons, fmt.Sprintf("service type preference %d", p.preference(r.Quote.Service.Type)+1))
+		}
+	}
+	return ranked
+}
+
+// Select returns the best quote according to the policy, or ErrNoQuoteSelected along with
+// the evaluation of every quote.
+func (p SelectionPolicy) Select(quotes []QuoteBase) (*Selection, error) {
+	s := &Selection{Quotes: p.Rank(quotes)}
+	if len(s.Quotes) == 0 || s.Quotes[0].Rejected {
+		return s, ErrNoQuoteSelected
+	}
+	s.Quote = s.Quotes[0].Quote
+	return s, nil
+}
+
+// SelectQuote applies policy to the returned quotes.
+func (r *CreateQuotesResponse) SelectQuote(policy SelectionPolicy) (*Selection, error) {
+	return policy.Select(r.Quotes)
+}
+
+// rejections returns why q violates the policy constraints.
+func (p SelectionPolicy) rejections(q QuoteBase) []string {
+	var reasons []string
+	if len(p.ServiceTypes) > 0 && p.preference(q.Service.Type) == len(p.ServiceTypes) {
+		reasons = append(reasons, fmt.Sprintf("service type %s not accepted", q.Service.Type))
+	}
+	if p.MaxAmount != nil {
+		amount := q.AmountMoney()
+		if cmp, err := amount.Cmp(*p.MaxAmount); err != nil {
+			reasons = append(reasons, fmt.Sprintf("currency %s differs from maximum amount currency %s",
+				q.Currency.Code, p.MaxAmount.Currency.Code))
+		} else if cmp > 0 {
+			reasons = append(reasons, fmt.Sprintf("amount %s exceeds maximum %s", amount, *p.MaxAmount))
+		}
+	}
+	if p.LatestDropoff != nil {
+		dropoff := estimatedDropoff(q)
+		switch {
+		case dropoff == nil:
+			reasons = append(reasons, "no estimated dropoff")
+		case dropoff.After(*p.LatestDropoff):
+			reasons = append(reasons, fmt.Sprintf("estimated dropoff %s is after %s",
+				dropoff.Format(time.RFC3339), p.LatestDropoff.Format(time.RFC3339)))
+		}
+	}
+	return reasons
+}
+
+// preference returns the position of t in ServiceTypes, or len(ServiceTypes) if absent.
+func (p SelectionPolicy) preference(t ServiceType) int {
+	for i, st := range p.ServiceTypes {
+		if st == t {
+			return i
+		}
+	}
+	return len(p.ServiceTypes)
+}
+
+func estimatedDropoff(q QuoteBase) *time.Time {
+	if q.EstimatedTimeline == nil {
+		return nil
+	}
+	return q.EstimatedTimeline.DropOff
+}
@@ -0,0 +1,84 @@
+package grabexpress_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+func TestSelectionPolicyRank(t *testing.T) {
+	now := time.Date(2021, 3, 4, 9, 0, 0, 0, time.UTC)
+	quote := func(name string, serviceType grabexpress.ServiceType, amount float64, dropoffIn time.Duration) grabexpress.QuoteBase {
+		q := grabexpress.QuoteBase{
+			Service:  grabexpress.Service{Name: name, Type: serviceType},
+			Currency: sgd,
+			Amount:   amount,
+		}
+		if dropoffIn > 0 {
+			dropoff := now.Add(dropoffIn)
+			q.EstimatedTimeline = &grabexpress.Timeline{DropOff: &dropoff}
+		}
+		return q
+	}
+	quotes := []grabexpress.QuoteBase{
+		quote("instant", grabexpress.ServiceTypeInstant, 12, time.Hour),
+		quote("same day", grabexpress.ServiceTypeSameDay, 8, 6*time.Hour),
+		quote("bulk", grabexpress.ServiceTypeBulk, 20, 0),
+	}
+	maxAmount := grabexpress.NewMoney(1500, sgd)
+	maxAmountIDR := grabexpress.NewMoney(1500, idr)
+	latest := now.Add(2 * time.Hour)
+
+	tests := []struct {
+		name     string
+		policy   grabexpress.SelectionPolicy
+		want     []string
+		rejected int
+	}{
+		{"cheapest", grabexpress.CheapestPolicy(), []string{"same day", "instant", "bulk"}, 0},
+		{"fastest", grabexpress.FastestPolicy(), []string{"instant", "same day", "bulk"}, 0},
+		{"default score", grabexpress.SelectionPolicy{}, []string{"same day", "instant", "bulk"}, 0},
+		{"preferred service types", grabexpress.SelectionPolicy{
+			ServiceTypes: []grabexpress.ServiceType{grabexpress.ServiceTypeBulk, grabexpress.ServiceTypeInstant},
+		}, []string{"bulk", "instant", "same day"}, 1},
+		{"maximum amount", grabexpress.SelectionPolicy{MaxAmount: &maxAmount}, []string{"same day", "instant", "bulk"}, 1},
+		{"maximum amount in another currency", grabexpress.SelectionPolicy{MaxAmount: &maxAmountIDR}, []string{"same day", "instant", "bulk"}, 3},
+		{"latest dropoff", grabexpress.SelectionPolicy{LatestDropoff: &latest}, []string{"instant", "same day", "bulk"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := tt.policy.Rank(quotes)
+			var names []string
+			rejected := 0
+			for i, r := range ranked {
+				names = append(names, r.Quote.Service.Name)
+				if r.Rejected {
+					rejected++
+					if r.Rank != 0 || len(r.Reasons) == 0 {
+						t.Errorf("rejected %s has rank %d and reasons %v", r.Quote.Service.Name, r.Rank, r.Reasons)
+					}
+				} else if r.Rank != i+1 {
+					t.Errorf("%s has rank %d, want %d", r.Quote.Service.Name, r.Rank, i+1)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.want) || rejected != tt.rejected {
+				t.Errorf("Rank() = %v with %d rejected, want %v with %d rejected", names, rejected, tt.want, tt.rejected)
+			}
+
+			selection, err := tt.policy.Select(quotes)
+			if tt.rejected == len(quotes) {
+				if !errors.Is(err, grabexpress.ErrNoQuoteSelected) {
+					t.Errorf("Select() error = %v, want ErrNoQuoteSelected", err)
+				}
+			} else if err != nil || selection.Quote.Service.Name != tt.want[0] {
+				t.Errorf("Select() = %+v, %v, want %s", selection, err, tt.want[0])
+			}
+			if len(selection.Explain()) != len(quotes) {
+				t.Errorf("Explain() = %v, want a line per quote", selection.Explain())
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+func newCreateDeliveryRequest(merchantOrderID string) *grabexpress.CreateDeliveryRequest {
+	return &grabexpress.CreateDeliveryRequest{
+		MerchantOrderID: merchantOrderID,
+		ServiceType:     grabexpress.ServiceTypeInstant,
+		Packages: []grabexpress.Package{{
+			Name:        "Documents",
+			Description: "Contract",
+			Quantity:    1,
+			Price:       10,
+			Dimensions:  grabexpress.Dimensions{Height: 1, Width: 20, Depth: 30, Weight: 100},
+		}},
+		Origin: grabexpress.Waypoint{
+			Address:     "1 Raffles Place",
+			Coordinates: grabexpress.Coordinates{Latitude: 1.2840, Longitude: 103.8514},
+		},
+		Destination: grabexpress.Waypoint{
+			Address:     "10 Bayfront Avenue",
+			Coordinates: grabexpress.Coordinates{Latitude: 1.2834, Longitude: 103.8607},
+		},
+		Sender:    grabexpress.Contact{FirstName: "Sam", Phone: "91234567"},
+		Recipient: grabexpress.Contact{FirstName: "Riley", Phone: "98765432"},
+	}
+}
+
+// countRequests returns how many requests the simulator received for method and path.
+func countRequests(srv *grabexpresstest.Server, method, path string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.Method == method && r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSimulatorLifecycle(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	created, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		advance time.Duration
+		want    grabexpress.OrderStatus
+	}{
+		{0, grabexpress.OrderStatusQueueing},
+		{time.Minute, grabexpress.OrderStatusAllocating},
+		{time.Minute, grabexpress.OrderStatusPickingUp},
+		{10 * time.Minute, grabexpress.OrderStatusInDelivery},
+		{20 * time.Minute, grabexpress.OrderStatusCompleted},
+	}
+	var elapsed time.Duration
+	for _, tt := range tests {
+		srv.Advance(tt.advance)
+		elapsed += tt.advance
+		resp, err := client.GetDelivery(ctx, created.DeliveryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != tt.want {
+			t.Errorf("status after %s = %s, want %s", elapsed, resp.Status, tt.want)
+		}
+	}
+}
+
+func TestSimulatorScriptedFailures(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	srv.Fail(grabexpresstest.Failure{Method: http.MethodPost, Path: "/v1/deliveries", Status: http.StatusBadGateway, Times: 1})
+
+	if _, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1")); !errors.Is(err, grabexpress.ErrServer) {
+		t.Fatalf("CreateDelivery() error = %v, want ErrServer", err)
+	}
+	if _, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1")); err != nil {
+		t.Fatalf("CreateDelivery() once the failure is consumed error = %v", err)
+	}
+	_, err = client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	var apiErr *grabexpress.Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusConflict {
+		t.Errorf("CreateDelivery() with a used merchant order ID error = %v, want 409 Conflict", err)
+	}
+}
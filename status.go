@@ -0,0 +1,180 @@
+package grabexpress
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Stage returns the position of s in the delivery lifecycle
+// QUEUING → ALLOCATING → PICKING_UP → IN_DELIVERY → COMPLETED, where a delivery may instead go
+// IN_RETURN → RETURNED after pickup, or end CANCELED or FAILED. All terminal statuses share the last stage.
+// Unknown statuses are at stage 0.
+func (s OrderStatus) Stage() int {
+	switch s {
+	case OrderStatusQueueing:
+		return 1
+	case OrderStatusAllocating:
+		return 2
+	case OrderStatusPickingUp:
+		return 3
+	case OrderStatusInDelivery:
+		return 4
+	case OrderStatusInReturn:
+		return 5
+	case OrderStatusCompleted, OrderStatusReturned, OrderStatusCanceled, OrderStatusFailed:
+		return 6
+	}
+	return 0
+}
+
+// After reports whether a delivery with status prev may later reach status s through legal transitions.
+// Statuses may be skipped, e.g. a delivery observed QUEUING and then PICKING_UP moved forward, and
+// a delivery observed PICKING_UP and then ALLOCATING lost its courier. A status does not come after itself.
+func (s OrderStatus) After(prev OrderStatus) bool {
+	if s == prev {
+		return false
+	}
+	seen := map[OrderStatus]bool{prev: true}
+	pending := []OrderStatus{prev}
+	for len(pending) > 0 {
+		from := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, next := range transitions[from] {
+			if next == s {
+				return true
+			}
+			if !seen[next] {
+				seen[next] = true
+				pending = append(pending, next)
+			}
+		}
+	}
+	return false
+}
+
+// ErrInvalidTransition is matched by the errors of ValidateTransition.
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// transitions lists the statuses each status may move to. A courier dropping a delivery
+// before pickup sends it back from PICKING_UP to ALLOCATING.
+var transitions = map[OrderStatus][]OrderStatus{
+	OrderStatusQueueing:   {OrderStatusAllocating, OrderStatusCanceled, OrderStatusFailed},
+	OrderStatusAllocating: {OrderStatusPickingUp, OrderStatusCanceled, OrderStatusFailed},
+	OrderStatusPickingUp:  {OrderStatusInDelivery, OrderStatusAllocating, OrderStatusCanceled, OrderStatusFailed},
+	OrderStatusInDelivery: {OrderStatusCompleted, OrderStatusInReturn, OrderStatusFailed},
+	OrderStatusInReturn:   {OrderStatusReturned, OrderStatusFailed},
+}
+
+// TransitionError is returned by ValidateTransition for an illegal transition.
+type TransitionError struct {
+	From OrderStatus
+	To   OrderStatus
+}
+
+// Error ...
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s: %s to %s", ErrInvalidTransition, e.From, e.To)
+}
+
+// Is makes errors.Is(err, ErrInvalidTransition) hold.
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// IsKnown reports whether s is one of the OrderStatus enum values.
+func (s OrderStatus) IsKnown() bool {
+	return s.Stage() > 0
+}
+
+// IsTerminal reports whether a delivery with status s will not change anymore.
+func (s OrderStatus) IsTerminal() bool {
+	switch s {
+	case OrderStatusCompleted, OrderStatusReturned, OrderStatusCanceled, OrderStatusFailed:
+		return true
+	}
+	return false
+}
+
+// IsActive reports whether a delivery with status s is still in progress.
+func (s OrderStatus) IsActive() bool {
+	return s.IsKnown() && !s.IsTerminal()
+}
+
+// CanCancel reports whether a delivery with status s may still be canceled, i.e. it was not picked up yet.
+func (s OrderStatus) CanCancel() bool {
+	switch s {
+	case OrderStatusQueueing, OrderStatusAllocating, OrderStatusPickingUp:
+		return true
+	}
+	return false
+}
+
+// NextStatuses returns the statuses a delivery with status s may move to.
+func (s OrderStatus) NextStatuses() []OrderStatus {
+	return append([]OrderStatus(nil), transitions[s]...)
+}
+
+// CanTransitionTo reports whether a delivery may move from status s to status to.
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns a *TransitionError unless a delivery may move from status from to status to.
+// Staying in the same known status is valid.
+func ValidateTransition(from, to OrderStatus) error {
+	if from == to && from.IsKnown() {
+		return nil
+	}
+	if !from.CanTransitionTo(to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// progressMilestones is the number of Timeline milestones of a successful delivery:
+// creation, allocation, pickup and dropoff.
+const progressMilestones = 4
+
+// Progress summarizes how far a delivery went, as recorded by its Timeline.
+type Progress struct {
+	Status OrderStatus
+	// Reached is the number of milestones reached out of Total: creation, allocation, pickup and dropoff.
+	Reached int
+	Total   int
+	// UpdatedAt is the time of the latest Timeline event.
+	UpdatedAt *time.Time
+}
+
+// Percent returns the share of milestones reached, between 0 and 100.
+func (p Progress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Reached * 100 / p.Total
+}
+
+// Progress derives the progress of the delivery from its Timeline.
+func (d *Delivery) Progress() Progress {
+	p := Progress{Status: d.Status, Total: progressMilestones}
+	t := d.Timeline
+	if t == nil {
+		return p
+	}
+	for _, reached := range []bool{t.Create != nil, t.Allocate != nil, t.Pickup != nil, t.DropOff != nil || t.Completed != nil} {
+		if reached {
+			p.Reached++
+		}
+	}
+	for _, at := range []*time.Time{t.Create, t.Allocate, t.Pickup, t.DropOff, t.Completed, t.Cancel, t.Return, t.Fail} {
+		if at != nil && (p.UpdatedAt == nil || at.After(*p.UpdatedAt)) {
+			p.UpdatedAt = at
+		}
+	}
+	return p
+}
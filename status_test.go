@@ -0,0 +1,78 @@
+package grabexpress_test
+
+import (
+	"errors"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+const (
+	queueing   = grabexpress.OrderStatusQueueing
+	allocating = grabexpress.OrderStatusAllocating
+	pickingUp  = grabexpress.OrderStatusPickingUp
+	inDelivery = grabexpress.OrderStatusInDelivery
+	inReturn   = grabexpress.OrderStatusInReturn
+	completed  = grabexpress.OrderStatusCompleted
+	returned   = grabexpress.OrderStatusReturned
+	canceled   = grabexpress.OrderStatusCanceled
+	failed     = grabexpress.OrderStatusFailed
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to grabexpress.OrderStatus
+		valid    bool
+	}{
+		{queueing, allocating, true},
+		{allocating, pickingUp, true},
+		{pickingUp, allocating, true},
+		{pickingUp, inDelivery, true},
+		{inDelivery, completed, true},
+		{inDelivery, inReturn, true},
+		{inReturn, returned, true},
+		{queueing, canceled, true},
+		{pickingUp, canceled, true},
+		{inReturn, failed, true},
+		{allocating, allocating, true},
+		{queueing, pickingUp, false},
+		{inDelivery, canceled, false},
+		{inReturn, completed, false},
+		{completed, inDelivery, false},
+		{canceled, allocating, false},
+		{"UNKNOWN", "UNKNOWN", false},
+	}
+	for _, tt := range tests {
+		err := grabexpress.ValidateTransition(tt.from, tt.to)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateTransition(%s, %s) error = %v", tt.from, tt.to, err)
+		}
+		var transitionErr *grabexpress.TransitionError
+		if !tt.valid && (!errors.Is(err, grabexpress.ErrInvalidTransition) || !errors.As(err, &transitionErr) ||
+			transitionErr.From != tt.from || transitionErr.To != tt.to) {
+			t.Errorf("ValidateTransition(%s, %s) error = %v, want a *TransitionError", tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestOrderStatusAfter(t *testing.T) {
+	tests := []struct {
+		s, prev grabexpress.OrderStatus
+		want    bool
+	}{
+		{pickingUp, queueing, true},
+		{completed, allocating, true},
+		{allocating, pickingUp, true},
+		{returned, inDelivery, true},
+		{canceled, inDelivery, false},
+		{completed, inReturn, false},
+		{queueing, allocating, false},
+		{pickingUp, pickingUp, false},
+		{allocating, completed, false},
+	}
+	for _, tt := range tests {
+		if got := tt.s.After(tt.prev); got != tt.want {
+			t.Errorf("%s.After(%s) = %t, want %t", tt.s, tt.prev, got, tt.want)
+		}
+	}
+}
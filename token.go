@@ -0,0 +1,165 @@
+package grabexpress
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// defaultTokenExpiryDelta is how long before its expiry a cached token is considered stale.
+const defaultTokenExpiryDelta = time.Minute
+
+// TokenStore persists OAuth tokens so that they can be shared across processes or survive restarts.
+// Load returns a nil token when nothing has been stored yet.
+type TokenStore interface {
+	Load(ctx context.Context) (*oauth2.Token, error)
+	Save(ctx context.Context, token *oauth2.Token) error
+}
+
+// TokenFetchFunc fetches a new access token from the token URL, sending its HTTP requests over hc.
+type TokenFetchFunc func(ctx context.Context, hc *http.Client) (*oauth2.Token, error)
+
+// TokenMiddleware wraps a TokenFetchFunc, e.g. to trace or measure token fetches. A middleware may pass
+// next a different *http.Client, e.g. one that instruments the requests of the fetch.
+type TokenMiddleware func(next TokenFetchFunc) TokenFetchFunc
+
+// tokenSource is a concurrency-safe cache of the client credentials access token.
+// Concurrent callers that find the cached token stale share a single refresh.
+type tokenSource struct {
+	config      *Client
+	store       TokenStore
+	expiryDelta time.Duration
+	fetch       TokenFetchFunc
+
+	mu    sync.Mutex
+	token *oauth2.Token
+	// rejected is the last access token the API rejected, which must not be reloaded from the store.
+	rejected string
+	flight   flightGroup
+}
+
+func newTokenSource(c *Client) *tokenSource {
+	delta := c.tokenExpiryDelta
+	if delta <= 0 {
+		delta = defaultTokenExpiryDelta
+	}
+	fetch := TokenFetchFunc(func(ctx context.Context, hc *http.Client) (*oauth2.Token, error) {
+		return c.oauth.Token(context.WithValue(ctx, oauth2.HTTPClient, hc))
+	})
+	for i := len(c.tokenMiddlewares) - 1; i >= 0; i-- {
+		fetch = c.tokenMiddlewares[i](fetch)
+	}
+	return &tokenSource{
+		config:      c,
+		store:       c.tokenStore,
+		expiryDelta: delta,
+		fetch:       fetch,
+	}
+}
+
+// Token returns the cached access token, refreshing it when it is missing or about to expire.
+func (ts *tokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	ts.mu.Lock()
+	if ts.valid(ts.token) {
+		token := ts.token
+		ts.mu.Unlock()
+		return token, nil
+	}
+	ts.mu.Unlock()
+
+	value, _, err := ts.flight.do(ctx, "token", func(ctx context.Context) (interface{}, error) {
+		token, err := ts.refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ts.mu.Lock()
+		ts.token = token
+		ts.mu.Unlock()
+		return token, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*oauth2.Token), nil
+}
+
+// invalidate drops the cached token if it still carries accessToken, e.g. after the API rejected it.
+func (ts *tokenSource) invalidate(accessToken string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.rejected = accessToken
+	if ts.token != nil && ts.token.AccessToken == accessToken {
+		ts.token = nil
+	}
+}
+
+func (ts *tokenSource) refresh(ctx context.Context) (*oauth2.Token, error) {
+	if ts.store != nil {
+		if token, err := ts.store.Load(ctx); err == nil && ts.valid(token) && !ts.isRejected(token) {
+			return token, nil
+		}
+	}
+	hc := ts.config.httpClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	token, err := ts.fetch(ctx, hc)
+	ts.config.metrics.observeTokenRefresh(err)
+	if err != nil {
+		return nil, err
+	}
+	if ts.store != nil {
+		// Persisting is best-effort: the token is still usable by this process.
+		_ = ts.store.Save(ctx, token)
+	}
+	return token, nil
+}
+
+func (ts *tokenSource) isRejected(token *oauth2.Token) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return token.AccessToken == ts.rejected
+}
+
+func (ts *tokenSource) valid(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	if token.Expiry.IsZero() {
+		return true
+	}
+	return time.Now().Add(ts.expiryDelta).Before(token.Expiry)
+}
+
+// Token returns the access token the client currently uses to authenticate its requests.
+func (c *Client) Token(ctx context.Context) (*oauth2.Token, error) {
+	return c.generateAuth(ctx)
+}
+
+// WithTokenStore configures a GrabExpress API client to load and persist its access token through store
+func WithTokenStore(store TokenStore) ClientOption {
+	return func(c *Client) error {
+		c.tokenStore = store
+		return nil
+	}
+}
+
+// WithTokenMiddleware configures a GrabExpress API client to pass every access token fetch through middlewares.
+// The first middleware is the outermost one. Tokens loaded from the TokenStore are not fetched.
+func WithTokenMiddleware(middlewares ...TokenMiddleware) ClientOption {
+	return func(c *Client) error {
+		c.tokenMiddlewares = append(c.tokenMiddlewares, middlewares...)
+		return nil
+	}
+}
+
+// WithTokenExpiryDelta configures how long before expiry a GrabExpress API client refreshes its access token
+func WithTokenExpiryDelta(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.tokenExpiryDelta = d
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+	"golang.org/x/oauth2"
+)
+
+// memoryTokenStore is a TokenStore shared by the clients of a test.
+type memoryTokenStore struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func (s *memoryTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token, nil
+}
+
+func (s *memoryTokenStore) Save(ctx context.Context, token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	return nil
+}
+
+func TestTokenRefreshAfterRevocation(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	store := &memoryTokenStore{}
+	client, err := srv.Client(grabexpress.WithTokenStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	resp, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, _ := store.Load(ctx)
+
+	srv.RevokeTokens()
+	if _, err := client.GetDelivery(ctx, resp.DeliveryID); !errors.Is(err, grabexpress.ErrUnauthorized) {
+		t.Fatalf("GetDelivery() with a revoked token error = %v, want ErrUnauthorized", err)
+	}
+	if _, err := client.GetDelivery(ctx, resp.DeliveryID); err != nil {
+		t.Fatalf("GetDelivery() after the revocation error = %v", err)
+	}
+	if n := countRequests(srv, http.MethodPost, "/grabid/v1/oauth2/token"); n != 2 {
+		t.Errorf("fetched %d tokens, want 2", n)
+	}
+	if stored, _ := store.Load(ctx); stored.AccessToken == revoked.AccessToken {
+		t.Error("the store still holds the revoked token")
+	}
+}
+
+func TestTokenMiddleware(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	var calls []string
+	trace := func(name string) grabexpress.TokenMiddleware {
+		return func(next grabexpress.TokenFetchFunc) grabexpress.TokenFetchFunc {
+			return func(ctx context.Context, hc *http.Client) (*oauth2.Token, error) {
+				if hc == nil {
+					t.Error("token fetch without an HTTP client")
+				}
+				calls = append(calls, name)
+				return next(ctx, hc)
+			}
+		}
+	}
+	client, err := srv.Client(grabexpress.WithTokenMiddleware(trace("outer"), trace("inner")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.Token(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The cached token is reused without fetching.
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "inner" {
+		t.Errorf("calls = %v, want [outer inner]", calls)
+	}
+}
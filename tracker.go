@@ -0,0 +1,331 @@
+package grabexpress
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// TrackerEventType ...
+type TrackerEventType string
+
+// TrackerEventType enum
+const (
+	// TrackerEventStatusChanged - the delivery status changed, or was observed for the first time
+	TrackerEventStatusChanged TrackerEventType = "STATUS_CHANGED"
+	// TrackerEventCourierAssigned - a courier was assigned to the delivery, or replaced
+	TrackerEventCourierAssigned TrackerEventType = "COURIER_ASSIGNED"
+	// TrackerEventCourierMoved - the courier coordinates changed
+	TrackerEventCourierMoved TrackerEventType = "COURIER_MOVED"
+	// TrackerEventTimelineUpdated - a new timeline milestone was recorded
+	TrackerEventTimelineUpdated TrackerEventType = "TIMELINE_UPDATED"
+	// TrackerEventError - polling the delivery failed
+	TrackerEventError TrackerEventType = "ERROR"
+)
+
+// Default polling intervals of a Tracker.
+const (
+	defaultTrackerFastInterval = 5 * time.Second
+	defaultTrackerSlowInterval = 30 * time.Second
+	defaultTrackerConcurrency  = 4
+	defaultTrackerBufferSize   = 64
+)
+
+var (
+	// ErrTrackerRunning is returned by Tracker.Run when the tracker is already running.
+	ErrTrackerRunning = errors.New("tracker already running")
+	// ErrTrackerStopped is returned by Tracker.Watch once Run returned.
+	ErrTrackerStopped = errors.New("tracker stopped")
+)
+
+// TrackerEvent is a change observed on a tracked delivery.
+type TrackerEvent struct {
+	Type       TrackerEventType
+	DeliveryID string
+	// Delivery is the latest state of the delivery. It is nil for TrackerEventError.
+	Delivery *Delivery
+	// Previous is the state before the change. It is nil on the first observation.
+	Previous *Delivery
+	Err      error
+	At       time.Time
+}
+
+// Tracker watches deliveries by polling GetDelivery, and emits their changes on a channel.
+// Deliveries are polled often while a courier is on the way, seldom while queuing,
+// and no more once they reach a terminal status.
+type Tracker struct {
+	client          *Client
+	intervals       map[OrderStatus]time.Duration
+	defaultInterval time.Duration
+	concurrency     int
+	events          chan TrackerEvent
+
+	mu      sync.Mutex
+	ctx     context.Context
+	stopped bool
+	pending map[string]bool
+	watched map[string]*watch
+	wg      sync.WaitGroup
+	sem     chan struct{}
+}
+
+// watch is the polling goroutine of a delivery.
+type watch struct {
+	cancel context.CancelFunc
+}
+
+// TrackerOption is the type of constructor options for NewTracker(...).
+type TrackerOption func(*Tracker)
+
+// NewTracker constructs a Tracker polling deliveries with client.
+func NewTracker(client *Client, options ...TrackerOption) *Tracker {
+	t := &Tracker{
+		client: client,
+		intervals: map[OrderStatus]time.Duration{
+			OrderStatusQueueing:   defaultTrackerSlowInterval,
+			OrderStatusAllocating: defaultTrackerSlowInterval,
+			OrderStatusPickingUp:  defaultTrackerFastInterval,
+			OrderStatusInDelivery: defaultTrackerFastInterval,
+			OrderStatusInReturn:   defaultTrackerFastInterval,
+		},
+		defaultInterval: defaultTrackerSlowInterval,
+		concurrency:     defaultTrackerConcurrency,
+		events:          make(chan TrackerEvent, defaultTrackerBufferSize),
+		pending:         map[string]bool{},
+		watched:         map[string]*watch{},
+	}
+	for _, option := range options {
+		option(t)
+	}
+	t.sem = make(chan struct{}, t.concurrency)
+	return t
+}
+
+// WithPollInterval configures how often a Tracker polls deliveries with the given status
+func WithPollInterval(status OrderStatus, d time.Duration) TrackerOption {
+	return func(t *Tracker) {
+		if d > 0 {
+			t.intervals[status] = d
+		}
+	}
+}
+
+// WithDefaultPollInterval configures how often a Tracker polls deliveries with no specific interval
+func WithDefaultPollInterval(d time.Duration) TrackerOption {
+	return func(t *Tracker) {
+		if d > 0 {
+			t.defaultInterval = d
+		}
+	}
+}
+
+// WithTrackerConcurrency configures how many GetDelivery calls a Tracker makes at once
+func WithTrackerConcurrency(n int) TrackerOption {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.concurrency = n
+		}
+	}
+}
+
+// WithTrackerBufferSize configures the capacity of the events channel of a Tracker
+func WithTrackerBufferSize(n int) TrackerOption {
+	return func(t *Tracker) {
+		if n >= 0 {
+			t.events = make(chan TrackerEvent, n)
+		}
+	}
+}
+
+// Events returns the channel on which changes are emitted. It is closed when Run returns.
+func (t *Tracker) Events() <-chan TrackerEvent {
+	return t.events
+}
+
+// Watch starts tracking the given deliveries. It may be called before or while the tracker runs,
+// and returns ErrTrackerStopped once Run returned.
+func (t *Tracker) Watch(deliveryIDs ...string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return ErrTrackerStopped
+	}
+	for _, id := range deliveryIDs {
+		if _, ok := t.watched[id]; ok {
+			continue
+		}
+		if t.ctx == nil {
+			t.pending[id] = true
+			continue
+		}
+		t.start(id)
+	}
+	return nil
+}
+
+// Unwatch stops tracking the given deliveries.
+func (t *Tracker) Unwatch(deliveryIDs ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range deliveryIDs {
+		delete(t.pending, id)
+		if w, ok := t.watched[id]; ok {
+			w.cancel()
+			delete(t.watched, id)
+		}
+	}
+}
+
+// Watching returns the IDs of the deliveries being tracked.
+func (t *Tracker) Watching() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]string, 0, len(t.pending)+len(t.watched))
+	for id := range t.pending {
+		ids = append(ids, id)
+	}
+	for id := range t.watched {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Run tracks the watched deliveries until ctx is done, then closes the events channel.
+func (t *Tracker) Run(ctx context.Context) error {
+	t.mu.Lock()
+	if t.ctx != nil {
+		t.mu.Unlock()
+		return ErrTrackerRunning
+	}
+	t.ctx = ctx
+	for id := range t.pending {
+		t.start(id)
+	}
+	t.pending = map[string]bool{}
+	t.mu.Unlock()
+
+	<-ctx.Done()
+	t.mu.Lock()
+	t.stopped = true
+	t.mu.Unlock()
+	t.wg.Wait()
+	close(t.events)
+	return ctx.Err()
+}
+
+// start polls deliveryID in its own goroutine. t.mu must be held.
+func (t *Tracker) start(deliveryID string) {
+	ctx, cancel := context.WithCancel(t.ctx)
+	w := &watch{cancel: cancel}
+	t.watched[deliveryID] = w
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer cancel()
+		t.track(ctx, deliveryID, w)
+	}()
+}
+
+func (t *Tracker) track(ctx context.Context, deliveryID string, w *watch) {
+	var prev *Delivery
+	for {
+		cur, err := t.poll(ctx, deliveryID)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			t.emit(ctx, TrackerEvent{Type: TrackerEventError, DeliveryID: deliveryID, Previous: prev, Err: err, At: time.Now()})
+		} else {
+			for _, event := range diffDeliveries(prev, cur) {
+				t.emit(ctx, event)
+			}
+			prev = cur
+			if cur.Status.IsTerminal() {
+				t.mu.Lock()
+				// Unwatch then Watch may have replaced it meanwhile.
+				if t.watched[deliveryID] == w {
+					delete(t.watched, deliveryID)
+				}
+				t.mu.Unlock()
+				return
+			}
+		}
+		if sleep(ctx, t.interval(prev)) != nil {
+			return
+		}
+	}
+}
+
+func (t *Tracker) poll(ctx context.Context, deliveryID string) (*Delivery, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-t.sem }()
+	resp, err := t.client.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Delivery, nil
+}
+
+func (t *Tracker) interval(d *Delivery) time.Duration {
+	if d != nil {
+		if interval, ok := t.intervals[d.Status]; ok {
+			return interval
+		}
+	}
+	return t.defaultInterval
+}
+
+func (t *Tracker) emit(ctx context.Context, event TrackerEvent) {
+	select {
+	case t.events <- event:
+	case <-ctx.Done():
+	}
+}
+
+// diffDeliveries returns the events describing how prev became cur.
+func diffDeliveries(prev, cur *Delivery) []TrackerEvent {
+	now := time.Now()
+	event := func(typ TrackerEventType) TrackerEvent {
+		return TrackerEvent{Type: typ, DeliveryID: cur.DeliveryID, Delivery: cur, Previous: prev, At: now}
+	}
+	var events []TrackerEvent
+	if prev == nil || prev.Status != cur.Status {
+		events = append(events, event(TrackerEventStatusChanged))
+	}
+	var prevCourier *Courier
+	if prev != nil {
+		prevCourier = prev.Courier
+	}
+	switch {
+	case cur.Courier == nil:
+	case prevCourier == nil || prevCourier.Phone != cur.Courier.Phone || prevCourier.Name != cur.Courier.Name:
+		events = append(events, event(TrackerEventCourierAssigned))
+	case prevCourier.Coordinates != cur.Courier.Coordinates:
+		events = append(events, event(TrackerEventCourierMoved))
+	}
+	if prev != nil && !sameTimeline(prev.Timeline, cur.Timeline) {
+		events = append(events, event(TrackerEventTimelineUpdated))
+	}
+	return events
+}
+
+func sameTimeline(a, b *Timeline) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	pairs := [][2]*time.Time{
+		{a.Create, b.Create}, {a.Allocate, b.Allocate}, {a.Pickup, b.Pickup}, {a.DropOff, b.DropOff},
+		{a.Completed, b.Completed}, {a.Cancel, b.Cancel}, {a.Return, b.Return}, {a.Fail, b.Fail},
+	}
+	for _, p := range pairs {
+		if (p[0] == nil) != (p[1] == nil) || (p[0] != nil && !p[0].Equal(*p[1])) {
+			return false
+		}
+	}
+	return true
+}
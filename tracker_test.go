@@ -0,0 +1,65 @@
+package grabexpress_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/grabexpresstest"
+)
+
+func TestTracker(t *testing.T) {
+	srv := grabexpresstest.NewServer()
+	defer srv.Close()
+	client, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	created, err := client.CreateDelivery(ctx, newCreateDeliveryRequest("ORDER-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := grabexpress.NewTracker(client, grabexpress.WithPollInterval(queueing, time.Millisecond))
+	if err := tracker.Watch(created.DeliveryID); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- tracker.Run(ctx) }()
+
+	var statuses []grabexpress.OrderStatus
+	for event := range tracker.Events() {
+		if event.Type != grabexpress.TrackerEventStatusChanged {
+			continue
+		}
+		statuses = append(statuses, event.Delivery.Status)
+		if event.Delivery.Status == queueing {
+			srv.SetStatus(created.DeliveryID, canceled, "")
+		}
+		if event.Delivery.Status.IsTerminal() {
+			break
+		}
+	}
+	if len(statuses) != 2 || statuses[1] != canceled {
+		t.Errorf("observed %v, want QUEUING then CANCELED", statuses)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if watching := tracker.Watching(); len(watching) != 0 {
+		t.Errorf("Watching() = %v once the delivery ended", watching)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if err := tracker.Watch(created.DeliveryID); !errors.Is(err, grabexpress.ErrTrackerStopped) {
+		t.Errorf("Watch() after Run error = %v, want ErrTrackerStopped", err)
+	}
+	if err := tracker.Run(context.Background()); !errors.Is(err, grabexpress.ErrTrackerRunning) {
+		t.Errorf("Run() again error = %v, want ErrTrackerRunning", err)
+	}
+}
@@ -0,0 +1,183 @@
+package grabexpress
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValidationError lists the problems found by Validate. It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Violations []FieldError
+}
+
+// Error ...
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Field + ": " + v.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+// Is makes errors.Is(err, ErrValidation) hold.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// WithRequestValidation configures a GrabExpress API client to validate requests before sending them
+func WithRequestValidation() ClientOption {
+	return func(c *Client) error {
+		c.validateRequests = true
+		return nil
+	}
+}
+
+// Validate checks the request for problems the API would reject, and returns a *ValidationError listing them all.
+func (r *CreateQuotesRequest) Validate() error {
+	v := &validator{}
+	if r.ServiceType != nil {
+		v.serviceType("serviceType", *r.ServiceType)
+	}
+	v.packages("packages", r.Packages)
+	v.waypoint("origin", r.Origin)
+	v.waypoint("destination", r.Destination)
+	return v.err()
+}
+
+// Validate checks the request for problems the API would reject, and returns a *ValidationError listing them all.
+func (r *CreateDeliveryRequest) Validate() error {
+	v := &validator{}
+	if strings.TrimSpace(r.MerchantOrderID) == "" {
+		v.add("merchantOrderID", "is required")
+	}
+	v.serviceType("serviceType", r.ServiceType)
+	if r.PaymentMethod != nil && *r.PaymentMethod != PaymentMethodCash && *r.PaymentMethod != PaymentMethodCashless {
+		v.add("paymentMethod", "unknown payment method %q", *r.PaymentMethod)
+	}
+	v.packages("packages", r.Packages)
+	v.waypoint("origin", r.Origin)
+	v.waypoint("destination", r.Destination)
+	v.contact("sender", r.Sender, r.Origin)
+	v.contact("recipient", r.Recipient, r.Destination)
+	if cod := r.CashOnDelivery; cod != nil {
+		if cod.Amount <= 0 {
+			v.add("cashOnDelivery.amount", "must be positive")
+		}
+		if r.PaymentMethod != nil && *r.PaymentMethod == PaymentMethodCash {
+			v.add("cashOnDelivery", "is only supported with the %s payment method", PaymentMethodCashless)
+		}
+	}
+	if s := r.Schedule; s != nil {
+		v.schedule("schedule", *s, time.Now())
+	}
+	return v.err()
+}
+
+// validator accumulates the violations found in a request.
+type validator struct {
+	violations []FieldError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.violations = append(v.violations, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.violations}
+}
+
+func (v *validator) serviceType(field string, t ServiceType) {
+	switch t {
+	case ServiceTypeInstant, ServiceTypeSameDay, ServiceTypeBulk:
+	case "":
+		v.add(field, "is required")
+	default:
+		v.add(field, "unknown service type %q", t)
+	}
+}
+
+func (v *validator) packages(field string, packages []Package) {
+	for i, p := range packages {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		if strings.TrimSpace(p.Name) == "" {
+			v.add(path+".name", "is required")
+		}
+		if p.Quantity <= 0 {
+			v.add(path+".quantity", "must be positive")
+		}
+		if p.Price < 0 {
+			v.add(path+".price", "must not be negative")
+		}
+		d := p.Dimensions
+		for _, dim := range []struct {
+			name  string
+			value int64
+		}{{"height", d.Height}, {"weight", d.Weight}, {"width", d.Width}, {"depth", d.Depth}} {
+			if dim.value < 0 {
+				v.add(path+".dimensions."+dim.name, "must not be negative")
+			}
+		}
+	}
+}
+
+func (v *validator) waypoint(field string, w Waypoint) {
+	if strings.TrimSpace(w.Address) == "" {
+		v.add(field+".address", "is required")
+	}
+	c := w.Coordinates
+	if c.Latitude < -90 || c.Latitude > 90 {
+		v.add(field+".coordinates.latitude", "must be between -90 and 90")
+	}
+	if c.Longitude < -180 || c.Longitude > 180 {
+		v.add(field+".coordinates.longitude", "must be between -180 and 180")
+	}
+	if c.Latitude == 0 && c.Longitude == 0 {
+		v.add(field+".coordinates", "are missing")
+	}
+	if w.CityCode != nil && CityCode(*w.CityCode).GetCountry().Code == "" {
+		v.add(field+".cityCode", "unsupported city %q", *w.CityCode)
+	}
+}
+
+func (v *validator) contact(field string, c Contact, w Waypoint) {
+	if strings.TrimSpace(c.FirstName) == "" {
+		v.add(field+".firstName", "is required")
+	}
+	if c.Email != "" && !strings.Contains(c.Email, "@") {
+		v.add(field+".email", "is not an email address")
+	}
+	if strings.TrimSpace(c.Phone) == "" {
+		v.add(field+".phone", "is required")
+		return
+	}
+	if w.CityCode == nil {
+		return
+	}
+	country := CityCode(*w.CityCode).GetCountry()
+	if country.ValidPhone(c.Phone) {
+		return
+	}
+	if national, err := country.NationalPhone(c.Phone); err == nil {
+		v.add(field+".phone", "must be in the %s national format, e.g. %s", country.Name, national)
+		return
+	}
+	v.add(field+".phone", "is not a valid %s phone number", country.Name)
+}
+
+func (v *validator) schedule(field string, s Schedule, now time.Time) {
+	from, to := s.PickupTimeFrom, s.PickupTimeTo
+	if from == nil || to == nil {
+		v.add(field, "requires both pickupTimeFrom and pickupTimeTo")
+		return
+	}
+	if !to.After(*from) {
+		v.add(field+".pickupTimeTo", "must be after pickupTimeFrom")
+	}
+	if to.Before(now) {
+		v.add(field+".pickupTimeTo", "must not be in the past")
+	}
+}
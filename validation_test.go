@@ -0,0 +1,98 @@
+package grabexpress_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+func TestCreateDeliveryRequestValidate(t *testing.T) {
+	singapore := string(grabexpress.CityCodeSingaporeSingapore)
+	cash := grabexpress.PaymentMethodCash
+	unknownPayment := grabexpress.PaymentMethod("CHEQUE")
+	past := time.Now().Add(-2 * time.Hour)
+	later := past.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		modify func(r *grabexpress.CreateDeliveryRequest)
+		want   []string
+	}{
+		{"valid", func(r *grabexpress.CreateDeliveryRequest) {}, nil},
+		{"valid national phone", func(r *grabexpress.CreateDeliveryRequest) { r.Destination.CityCode = &singapore }, nil},
+		{"missing merchant order ID", func(r *grabexpress.CreateDeliveryRequest) { r.MerchantOrderID = " " }, []string{"merchantOrderID"}},
+		{"unknown service type", func(r *grabexpress.CreateDeliveryRequest) { r.ServiceType = "ROCKET" }, []string{"serviceType"}},
+		{"unknown payment method", func(r *grabexpress.CreateDeliveryRequest) { r.PaymentMethod = &unknownPayment }, []string{"paymentMethod"}},
+		{"invalid package", func(r *grabexpress.CreateDeliveryRequest) {
+			r.Packages[0].Quantity = 0
+			r.Packages[0].Dimensions.Weight = -1
+		}, []string{"packages[0].quantity", "packages[0].dimensions.weight"}},
+		{"missing coordinates", func(r *grabexpress.CreateDeliveryRequest) {
+			r.Origin.Coordinates = grabexpress.Coordinates{}
+		}, []string{"origin.coordinates"}},
+		{"latitude out of range", func(r *grabexpress.CreateDeliveryRequest) {
+			r.Origin.Coordinates.Latitude = 91
+		}, []string{"origin.coordinates.latitude"}},
+		{"unsupported city", func(r *grabexpress.CreateDeliveryRequest) {
+			city := "XX_XXX"
+			r.Origin.CityCode = &city
+		}, []string{"origin.cityCode"}},
+		{"international phone", func(r *grabexpress.CreateDeliveryRequest) {
+			r.Destination.CityCode = &singapore
+			r.Recipient.Phone = "+65 9876 5432"
+		}, []string{"recipient.phone"}},
+		{"missing contact", func(r *grabexpress.CreateDeliveryRequest) {
+			r.Sender = grabexpress.Contact{Email: "sam"}
+		}, []string{"sender.firstName", "sender.email", "sender.phone"}},
+		{"cash on delivery with cash", func(r *grabexpress.CreateDeliveryRequest) {
+			r.PaymentMethod = &cash
+			r.CashOnDelivery = &grabexpress.CashOnDelivery{Amount: 0}
+		}, []string{"cashOnDelivery.amount", "cashOnDelivery"}},
+		{"schedule in the past", func(r *grabexpress.CreateDeliveryRequest) {
+			r.Schedule = &grabexpress.Schedule{PickupTimeFrom: &later, PickupTimeTo: &past}
+		}, []string{"schedule.pickupTimeTo", "schedule.pickupTimeTo"}},
+		{"incomplete schedule", func(r *grabexpress.CreateDeliveryRequest) {
+			r.Schedule = &grabexpress.Schedule{PickupTimeFrom: &later}
+		}, []string{"schedule"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCreateDeliveryRequest("ORDER-1")
+			tt.modify(r)
+			err := r.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			var validationErr *grabexpress.ValidationError
+			if !errors.As(err, &validationErr) || !errors.Is(err, grabexpress.ErrValidation) {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			var fields []string
+			for _, v := range validationErr.Violations {
+				fields = append(fields, v.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("violations of %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateQuotesRequestValidate(t *testing.T) {
+	d := newCreateDeliveryRequest("ORDER-1")
+	req := &grabexpress.CreateQuotesRequest{Packages: d.Packages, Origin: d.Origin, Destination: d.Destination}
+	if err := req.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	req.Destination = grabexpress.Waypoint{}
+	var validationErr *grabexpress.ValidationError
+	if err := req.Validate(); !errors.As(err, &validationErr) || len(validationErr.Violations) != 2 {
+		t.Errorf("Validate() without destination error = %v, want 2 violations", err)
+	}
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Authenticator verifies that a callback was sent by GrabExpress.
+type Authenticator interface {
+	Authenticate(r *http.Request, body []byte) error
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(r *http.Request, body []byte) error
+
+// Authenticate ...
+func (f AuthenticatorFunc) Authenticate(r *http.Request, body []byte) error {
+	return f(r, body)
+}
+
+// BearerToken authenticates callbacks carrying token in their Authorization header.
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request, body []byte) error {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !equal(got, token) {
+			return ErrUnauthenticated
+		}
+		return nil
+	})
+}
+
+// BasicAuth authenticates callbacks carrying the given HTTP basic credentials.
+func BasicAuth(username, password string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request, body []byte) error {
+		u, p, ok := r.BasicAuth()
+		if !ok || password == "" || !equal(u, username) || !equal(p, password) {
+			return ErrUnauthenticated
+		}
+		return nil
+	})
+}
+
+// HMACSHA256 authenticates callbacks whose header holds the hex-encoded HMAC-SHA256 of their body keyed with secret.
+func HMACSHA256(header, secret string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request, body []byte) error {
+		got, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(header), "sha256="))
+		if err != nil || secret == "" {
+			return ErrUnauthenticated
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			return ErrUnauthenticated
+		}
+		return nil
+	})
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
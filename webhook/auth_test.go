@@ -0,0 +1,57 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rgaquino/grabexpress-go/webhook"
+)
+
+func TestAuthenticators(t *testing.T) {
+	body := []byte(`{"deliveryID":"D1","status":"COMPLETED"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name   string
+		auth   webhook.Authenticator
+		header map[string]string
+		user   string
+		pass   string
+		wantOK bool
+	}{
+		{"bearer", webhook.BearerToken("secret"), map[string]string{"Authorization": "Bearer secret"}, "", "", true},
+		{"bearer wrong token", webhook.BearerToken("secret"), map[string]string{"Authorization": "Bearer guess"}, "", "", false},
+		{"bearer empty token", webhook.BearerToken(""), map[string]string{"Authorization": "Bearer "}, "", "", false},
+		{"basic", webhook.BasicAuth("grab", "secret"), nil, "grab", "secret", true},
+		{"basic wrong password", webhook.BasicAuth("grab", "secret"), nil, "grab", "guess", false},
+		{"basic missing", webhook.BasicAuth("grab", "secret"), nil, "", "", false},
+		{"hmac", webhook.HMACSHA256("X-Signature", "secret"), map[string]string{"X-Signature": signature}, "", "", true},
+		{"hmac prefixed", webhook.HMACSHA256("X-Signature", "secret"), map[string]string{"X-Signature": "sha256=" + signature}, "", "", true},
+		{"hmac wrong secret", webhook.HMACSHA256("X-Signature", "other"), map[string]string{"X-Signature": signature}, "", "", false},
+		{"hmac not hex", webhook.HMACSHA256("X-Signature", "secret"), map[string]string{"X-Signature": "zz"}, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/webhook", nil)
+			for name, value := range tt.header {
+				r.Header.Set(name, value)
+			}
+			if tt.user != "" {
+				r.SetBasicAuth(tt.user, tt.pass)
+			}
+			err := tt.auth.Authenticate(r, body)
+			if tt.wantOK && err != nil {
+				t.Errorf("Authenticate() error = %v", err)
+			}
+			if !tt.wantOK && !errors.Is(err, webhook.ErrUnauthenticated) {
+				t.Errorf("Authenticate() error = %v, want ErrUnauthenticated", err)
+			}
+		})
+	}
+}
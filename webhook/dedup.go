@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+// Seen is the latest event handled for a delivery.
+type Seen struct {
+	Status    grabexpress.OrderStatus
+	Timestamp time.Time
+}
+
+// SeenStore remembers the latest event handled for each delivery.
+type SeenStore interface {
+	// Last returns the latest event recorded for deliveryID, if any.
+	Last(ctx context.Context, deliveryID string) (seen Seen, ok bool, err error)
+	// Record stores seen as the latest event handled for deliveryID.
+	Record(ctx context.Context, deliveryID string, seen Seen) error
+}
+
+// Deduplicator drops duplicated and out-of-order events, so that handlers only see the updates of each
+// delivery in the order GrabExpress timestamped them. Events are ordered by Event.Timestamp, then, between events
+// with the same timestamp, by lifecycle stage. A delivery may thus go back to an earlier status, e.g. from
+// PICKING_UP to ALLOCATING when its courier drops it, while redelivered events are still dropped.
+type Deduplicator struct {
+	store SeenStore
+
+	mu    sync.Mutex
+	locks map[string]*deliveryLock
+}
+
+type deliveryLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewDeduplicator constructs a Deduplicator remembering handled events in store.
+func NewDeduplicator(store SeenStore) *Deduplicator {
+	return &Deduplicator{store: store, locks: map[string]*deliveryLock{}}
+}
+
+// WithDeduplicator configures a Handler to dispatch only the events d lets through
+func WithDeduplicator(d *Deduplicator) Option {
+	return func(h *Handler) error {
+		h.dedup = d
+		return nil
+	}
+}
+
+// Wrap returns a HandlerFunc passing to fn only the events newer than the last one handled for their delivery.
+// An event is recorded as seen once fn handled it successfully, so that a failed event can be delivered again.
+func (d *Deduplicator) Wrap(fn HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, event *Event) error {
+		unlock := d.lock(event.DeliveryID)
+		defer unlock()
+
+		last, ok, err := d.store.Last(ctx, event.DeliveryID)
+		if err != nil {
+			return err
+		}
+		if ok && !newer(event, last) {
+			return nil
+		}
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+		return d.store.Record(ctx, event.DeliveryID, Seen{Status: event.Status, Timestamp: event.Timestamp})
+	}
+}
+
+// newer reports whether event comes after the last event handled for its delivery.
+func newer(event *Event, last Seen) bool {
+	if !event.Timestamp.Equal(last.Timestamp) {
+		return event.Timestamp.After(last.Timestamp)
+	}
+	return event.Status.Stage() > last.Status.Stage()
+}
+
+// lock serializes the handling of events of the same delivery.
+func (d *Deduplicator) lock(deliveryID string) func() {
+	d.mu.Lock()
+	l, ok := d.locks[deliveryID]
+	if !ok {
+		l = &deliveryLock{}
+		d.locks[deliveryID] = l
+	}
+	l.refs++
+	d.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		d.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(d.locks, deliveryID)
+		}
+		d.mu.Unlock()
+	}
+}
+
+// MemorySeenStore is a SeenStore kept in memory, suitable for a single process.
+type MemorySeenStore struct {
+	mu   sync.RWMutex
+	seen map[string]Seen
+}
+
+// NewMemorySeenStore ...
+func NewMemorySeenStore() *MemorySeenStore {
+	return &MemorySeenStore{seen: map[string]Seen{}}
+}
+
+// Last ...
+func (s *MemorySeenStore) Last(ctx context.Context, deliveryID string) (Seen, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen, ok := s.seen[deliveryID]
+	return seen, ok, nil
+}
+
+// Record ...
+func (s *MemorySeenStore) Record(ctx context.Context, deliveryID string, seen Seen) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen[deliveryID] = seen
+	return nil
+}
@@ -0,0 +1,107 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/webhook"
+)
+
+func TestDeduplicator(t *testing.T) {
+	base := time.Date(2021, 3, 4, 5, 0, 0, 0, time.UTC)
+	type event struct {
+		status grabexpress.OrderStatus
+		minute int
+	}
+	var (
+		allocating = grabexpress.OrderStatusAllocating
+		pickingUp  = grabexpress.OrderStatusPickingUp
+		inDelivery = grabexpress.OrderStatusInDelivery
+		completed  = grabexpress.OrderStatusCompleted
+	)
+	tests := []struct {
+		name   string
+		events []event
+		want   []grabexpress.OrderStatus
+	}{
+		{
+			name:   "in order",
+			events: []event{{allocating, 1}, {pickingUp, 2}, {inDelivery, 3}, {completed, 4}},
+			want:   []grabexpress.OrderStatus{allocating, pickingUp, inDelivery, completed},
+		},
+		{
+			name:   "redelivered",
+			events: []event{{allocating, 1}, {allocating, 1}, {pickingUp, 2}, {pickingUp, 2}},
+			want:   []grabexpress.OrderStatus{allocating, pickingUp},
+		},
+		{
+			name:   "out of order",
+			events: []event{{allocating, 1}, {inDelivery, 3}, {pickingUp, 2}, {completed, 4}},
+			want:   []grabexpress.OrderStatus{allocating, inDelivery, completed},
+		},
+		{
+			name:   "redelivered after a reallocation",
+			events: []event{{allocating, 1}, {pickingUp, 2}, {allocating, 1}, {pickingUp, 2}, {allocating, 1}},
+			want:   []grabexpress.OrderStatus{allocating, pickingUp},
+		},
+		{
+			name:   "reallocated",
+			events: []event{{allocating, 1}, {pickingUp, 2}, {allocating, 3}, {pickingUp, 4}, {pickingUp, 2}},
+			want:   []grabexpress.OrderStatus{allocating, pickingUp, allocating, pickingUp},
+		},
+		{
+			name:   "same timestamp",
+			events: []event{{pickingUp, 1}, {allocating, 1}, {pickingUp, 1}},
+			want:   []grabexpress.OrderStatus{pickingUp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []grabexpress.OrderStatus
+			d := webhook.NewDeduplicator(webhook.NewMemorySeenStore())
+			handle := d.Wrap(func(ctx context.Context, event *webhook.Event) error {
+				got = append(got, event.Status)
+				return nil
+			})
+			for _, e := range tt.events {
+				event := &webhook.Event{DeliveryID: "D1", Status: e.status, Timestamp: base.Add(time.Duration(e.minute) * time.Minute)}
+				if err := handle(context.Background(), event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("handled %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeduplicatorRetriesFailedEvents(t *testing.T) {
+	fail := true
+	calls := 0
+	d := webhook.NewDeduplicator(webhook.NewMemorySeenStore())
+	handle := d.Wrap(func(ctx context.Context, event *webhook.Event) error {
+		calls++
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+	event := &webhook.Event{DeliveryID: "D1", Status: grabexpress.OrderStatusCompleted, Timestamp: time.Now()}
+	if err := handle(context.Background(), event); err == nil {
+		t.Fatal("handle() succeeded despite the failing handler")
+	}
+	fail = false
+	for i := 0; i < 2; i++ {
+		if err := handle(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
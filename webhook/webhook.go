@@ -0,0 +1,193 @@
+// Package webhook receives the delivery status callbacks GrabExpress pushes to partner webhooks.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+)
+
+// defaultMaxBodySize bounds the size of callback bodies read by the Handler.
+const defaultMaxBodySize = 1 << 20
+
+var (
+	ErrUnauthenticated      = errors.New("webhook callback not authenticated")
+	ErrAuthenticatorMissing = errors.New("webhook authenticator missing")
+)
+
+// Event is a delivery status update pushed by GrabExpress.
+type Event struct {
+	DeliveryID      string                   `json:"deliveryID"`
+	MerchantOrderID string                   `json:"merchantOrderID"`
+	Status          grabexpress.OrderStatus  `json:"status"`
+	TrackingURL     string                   `json:"trackingURL,omitempty"`
+	Courier         *grabexpress.Courier     `json:"courier,omitempty"`
+	Timeline        *grabexpress.Timeline    `json:"timeline,omitempty"`
+	AdvanceInfo     *grabexpress.AdvanceInfo `json:"advanceInfo,omitempty"`
+	Timestamp       time.Time                `json:"timestamp"`
+	// Raw is the undecoded callback body.
+	Raw json.RawMessage `json:"-"`
+}
+
+// HandlerFunc handles a webhook event. Returning an error answers the callback with a 5xx status,
+// so that GrabExpress delivers it again later.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Handler is an http.Handler receiving GrabExpress delivery status callbacks.
+type Handler struct {
+	authenticator Authenticator
+	maxBodySize   int64
+	dedup         *Deduplicator
+
+	mu       sync.RWMutex
+	byStatus map[grabexpress.OrderStatus][]HandlerFunc
+	any      []HandlerFunc
+}
+
+// Option is the type of constructor options for NewHandler(...).
+type Option func(*Handler) error
+
+// NewHandler constructs a new Handler. An Authenticator is required.
+func NewHandler(options ...Option) (*Handler, error) {
+	h := &Handler{
+		maxBodySize: defaultMaxBodySize,
+		byStatus:    map[grabexpress.OrderStatus][]HandlerFunc{},
+	}
+	for _, option := range options {
+		if err := option(h); err != nil {
+			return nil, err
+		}
+	}
+	if h.authenticator == nil {
+		return nil, ErrAuthenticatorMissing
+	}
+	return h, nil
+}
+
+// WithAuthenticator configures a Handler to authenticate callbacks with a
+func WithAuthenticator(a Authenticator) Option {
+	return func(h *Handler) error {
+		h.authenticator = a
+		return nil
+	}
+}
+
+// WithMaxBodySize configures the maximum size of callback bodies accepted by a Handler
+func WithMaxBodySize(n int64) Option {
+	return func(h *Handler) error {
+		h.maxBodySize = n
+		return nil
+	}
+}
+
+// On registers fn to handle events with the given status.
+func (h *Handler) On(status grabexpress.OrderStatus, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.byStatus[status] = append(h.byStatus[status], fn)
+}
+
+// OnAny registers fn to handle events of every status.
+func (h *Handler) OnAny(fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.any = append(h.any, fn)
+}
+
+// ServeHTTP authenticates, decodes and dispatches a callback.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, h.maxBodySize+1))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > h.maxBodySize {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := h.authenticator.Authenticate(r, body); err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	event, err := Decode(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dispatch := h.Dispatch
+	if h.dedup != nil {
+		dispatch = h.dedup.Wrap(dispatch)
+	}
+	if err := dispatch(r.Context(), event); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Dispatch passes event to the handlers registered for its status, then to those registered for any status.
+// It stops at the first error.
+func (h *Handler) Dispatch(ctx context.Context, event *Event) error {
+	h.mu.RLock()
+	fns := append(append([]HandlerFunc{}, h.byStatus[event.Status]...), h.any...)
+	h.mu.RUnlock()
+	for _, fn := range fns {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode parses a callback body into an Event.
+func Decode(body []byte) (*Event, error) {
+	var payload struct {
+		Event
+		Timestamp json.RawMessage `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	event := payload.Event
+	if event.DeliveryID == "" {
+		return nil, errors.New("deliveryID missing")
+	}
+	if event.Status == "" {
+		return nil, errors.New("status missing")
+	}
+	timestamp, err := decodeTimestamp(payload.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	event.Timestamp = timestamp
+	event.Raw = append(json.RawMessage{}, body...)
+	return &event, nil
+}
+
+// decodeTimestamp accepts either Unix seconds or an RFC 3339 string.
+func decodeTimestamp(raw json.RawMessage) (time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, nil
+	}
+	var seconds int64
+	if err := json.Unmarshal(raw, &seconds); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return time.Time{}, errors.New("invalid timestamp")
+	}
+	return t, nil
+}
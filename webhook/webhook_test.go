@@ -0,0 +1,105 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	grabexpress "github.com/rgaquino/grabexpress-go"
+	"github.com/rgaquino/grabexpress-go/webhook"
+)
+
+func TestDecode(t *testing.T) {
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		name    string
+		body    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"unix timestamp", `{"deliveryID":"D1","status":"ALLOCATING","timestamp":1614834367}`, at, false},
+		{"RFC 3339 timestamp", `{"deliveryID":"D1","status":"ALLOCATING","timestamp":"2021-03-04T05:06:07Z"}`, at, false},
+		{"no timestamp", `{"deliveryID":"D1","status":"ALLOCATING"}`, time.Time{}, false},
+		{"invalid timestamp", `{"deliveryID":"D1","status":"ALLOCATING","timestamp":"yesterday"}`, time.Time{}, true},
+		{"no delivery ID", `{"status":"ALLOCATING"}`, time.Time{}, true},
+		{"no status", `{"deliveryID":"D1"}`, time.Time{}, true},
+		{"not JSON", `status=ALLOCATING`, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := webhook.Decode([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if event.DeliveryID != "D1" || event.Status != grabexpress.OrderStatusAllocating {
+				t.Errorf("Decode() = %+v", event)
+			}
+			if !event.Timestamp.Equal(tt.want) {
+				t.Errorf("Timestamp = %s, want %s", event.Timestamp, tt.want)
+			}
+			if string(event.Raw) != tt.body {
+				t.Errorf("Raw = %s, want the body", event.Raw)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var handled []grabexpress.OrderStatus
+	h, err := webhook.NewHandler(webhook.WithAuthenticator(webhook.BearerToken("secret")), webhook.WithMaxBodySize(128))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.On(grabexpress.OrderStatusCompleted, func(ctx context.Context, event *webhook.Event) error {
+		handled = append(handled, event.Status)
+		return nil
+	})
+	h.OnAny(func(ctx context.Context, event *webhook.Event) error {
+		if event.Status == grabexpress.OrderStatusFailed {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		body   string
+		want   int
+	}{
+		{"dispatched", http.MethodPost, "secret", `{"deliveryID":"D1","status":"COMPLETED"}`, http.StatusOK},
+		{"not POST", http.MethodGet, "secret", ``, http.StatusMethodNotAllowed},
+		{"unauthenticated", http.MethodPost, "guess", `{"deliveryID":"D1","status":"COMPLETED"}`, http.StatusUnauthorized},
+		{"malformed", http.MethodPost, "secret", `{"status":"COMPLETED"}`, http.StatusBadRequest},
+		{"too large", http.MethodPost, "secret", `{"deliveryID":"` + strings.Repeat("D", 200) + `"}`, http.StatusRequestEntityTooLarge},
+		{"handler failed", http.MethodPost, "secret", `{"deliveryID":"D1","status":"FAILED"}`, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body))
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+	if len(handled) != 1 {
+		t.Errorf("handled %v, want a single COMPLETED event", handled)
+	}
+}
+
+func TestNewHandlerRequiresAuthenticator(t *testing.T) {
+	if _, err := webhook.NewHandler(); !errors.Is(err, webhook.ErrAuthenticatorMissing) {
+		t.Errorf("NewHandler() error = %v, want ErrAuthenticatorMissing", err)
+	}
+}